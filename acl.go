@@ -0,0 +1,379 @@
+package kintone
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// AppACL はアプリのアクセス権。先頭の設定が優先される
+type AppACL []*AppRight
+
+// AppRight はアプリのアクセス権の設定
+type AppRight struct {
+	Entity           Entity `json:"entity"`
+	IncludeSubs      bool   `json:"includeSubs"`
+	AppEditable      bool   `json:"appEditable"`
+	RecordViewable   bool   `json:"recordViewable"`
+	RecordAddable    bool   `json:"recordAddable"`
+	RecordEditable   bool   `json:"recordEditable"`
+	RecordDeletable  bool   `json:"recordDeletable"`
+	RecordImportable bool   `json:"recordImportable"`
+	RecordExportable bool   `json:"recordExportable"`
+}
+
+// RecordACL はレコードのアクセス権。先頭の設定が優先される
+type RecordACL []*RecordRight
+
+// RecordRight は条件に一致するレコードのアクセス権
+type RecordRight struct {
+	FilterCond string               `json:"filterCond"`
+	Entities   []*RecordRightEntity `json:"entities"`
+}
+
+// RecordRightEntity はレコードのアクセス権の対象と権限
+type RecordRightEntity struct {
+	Entity      Entity `json:"entity"`
+	Viewable    bool   `json:"viewable"`
+	Editable    bool   `json:"editable"`
+	Deletable   bool   `json:"deletable"`
+	IncludeSubs bool   `json:"includeSubs"`
+}
+
+// FieldACL はフィールドのアクセス権
+type FieldACL []*FieldRight
+
+// FieldRight はフィールドのアクセス権
+type FieldRight struct {
+	Code     string              `json:"code"`
+	Entities []*FieldRightEntity `json:"entities"`
+}
+
+// FieldRightEntity はフィールドのアクセス権の対象と権限
+type FieldRightEntity struct {
+	Accessibility string `json:"accessibility"` // READ, WRITE, NONE
+	Entity        Entity `json:"entity"`
+	IncludeSubs   bool   `json:"includeSubs"`
+}
+
+// ReadAppACL はアプリのアクセス権を返す
+func (repo *Repository) ReadAppACL(appID int) (AppACL, error) {
+	raw := struct {
+		Rights AppACL `json:"rights"`
+	}{}
+	err := repo.readAppSetting(APIEndpointAppACL, appID, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return raw.Rights, nil
+}
+
+// UpdateAppACL はアプリのアクセス権を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateAppACL(appID int, acl AppACL, revision string) (string, error) {
+	raw := struct {
+		Rights AppACL `json:"rights"`
+	}{acl}
+	return repo.updateAppSetting(APIEndpointPreviewAppACL, appID, &raw, revision)
+}
+
+// ReadRecordACL はレコードのアクセス権を返す
+func (repo *Repository) ReadRecordACL(appID int) (RecordACL, error) {
+	raw := struct {
+		Rights RecordACL `json:"rights"`
+	}{}
+	err := repo.readAppSetting(APIEndpointRecordACL, appID, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return raw.Rights, nil
+}
+
+// UpdateRecordACL はレコードのアクセス権を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateRecordACL(appID int, acl RecordACL, revision string) (string, error) {
+	raw := struct {
+		Rights RecordACL `json:"rights"`
+	}{acl}
+	return repo.updateAppSetting(APIEndpointPreviewRecordACL, appID, &raw, revision)
+}
+
+// ReadFieldACL はフィールドのアクセス権を返す
+func (repo *Repository) ReadFieldACL(appID int) (FieldACL, error) {
+	raw := struct {
+		Rights FieldACL `json:"rights"`
+	}{}
+	err := repo.readAppSetting(APIEndpointFieldACL, appID, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return raw.Rights, nil
+}
+
+// UpdateFieldACL はフィールドのアクセス権を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateFieldACL(appID int, acl FieldACL, revision string) (string, error) {
+	raw := struct {
+		Rights FieldACL `json:"rights"`
+	}{acl}
+	return repo.updateAppSetting(APIEndpointPreviewFieldACL, appID, &raw, revision)
+}
+
+// evaluateLimit は一度にアクセス権を評価できるレコードの最大数
+const evaluateLimit = 100
+
+// RecordPermission はレコードとフィールドに対するアクセス権の評価結果
+type RecordPermission struct {
+	ID     string                      `json:"id"`
+	Record *RecordAccess               `json:"record"`
+	Fields map[string]*FieldPermission `json:"fields"`
+}
+
+// RecordAccess はレコードに対する権限
+type RecordAccess struct {
+	Viewable  bool `json:"viewable"`
+	Editable  bool `json:"editable"`
+	Deletable bool `json:"deletable"`
+}
+
+// FieldPermission はフィールドに対する権限
+type FieldPermission struct {
+	Viewable bool `json:"viewable"`
+	Editable bool `json:"editable"`
+}
+
+// EvaluateRecordACL はレコード ID ごとのアクセス権を評価する
+// 評価されるのは API を実行するユーザーの権限。他のユーザーの権限はそのユーザーの Repository で評価する
+// 100 件を超える場合は 100 件ずつ並行して評価する
+func (repo *Repository) EvaluateRecordACL(ctx context.Context, appID int, ids []string) ([]*RecordPermission, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	results := make([][]*RecordPermission, (len(ids)+evaluateLimit-1)/evaluateLimit)
+	eg, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < len(ids); i += evaluateLimit {
+		end := i + evaluateLimit
+		if end > len(ids) {
+			end = len(ids)
+		}
+		n, _ids := i/evaluateLimit, ids[i:end]
+		eg.Go(func() error {
+			ps, err := repo.evaluateRecordACL(ctx, appID, _ids)
+			results[n] = ps
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var ps []*RecordPermission
+	for _, _ps := range results {
+		ps = append(ps, _ps...)
+	}
+	return ps, nil
+}
+
+// evaluate 100 records
+func (repo *Repository) evaluateRecordACL(ctx context.Context, appID int, ids []string) ([]*RecordPermission, error) {
+	select {
+	case repo.Token <- struct{}{}: // acquire token
+		defer func() {
+			<-repo.Token
+		}()
+	case <-ctx.Done(): // cancelled
+		return nil, errors.New("canceled")
+	}
+
+	body, err := json.Marshal(struct {
+		App int      `json:"app"`
+		IDs []string `json:"ids"`
+	}{appID, ids})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := repo.Client.getWithBody(APIEndpointRecordsACLEvaluate, body)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := struct {
+		Rights []*RecordPermission `json:"rights"`
+	}{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return raw.Rights, nil
+}
+
+// AppPermissions はアプリのアクセス権の設定
+type AppPermissions struct {
+	App    *App
+	AppACL AppACL
+	Record RecordACL
+	Field  FieldACL
+}
+
+// PermissionMatrix はアプリごとのアクセス権の設定
+type PermissionMatrix []*AppPermissions
+
+// ReadPermissionMatrix はアプリのアクセス権、レコードのアクセス権、フィールドのアクセス権を返す
+// appIDs を指定しない場合は全てのアプリを対象にする
+func (repo *Repository) ReadPermissionMatrix(ctx context.Context, appIDs ...int) (PermissionMatrix, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var apps []*App
+	if len(appIDs) == 0 {
+		var err error
+		apps, err = repo.ListApps(nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "list apps failed")
+		}
+	} else {
+		for _, id := range appIDs {
+			app, err := repo.ReadApp(id)
+			if err != nil {
+				return nil, errors.Wrapf(err, "read app %d failed", id)
+			}
+			apps = append(apps, app)
+		}
+	}
+
+	m := make(PermissionMatrix, len(apps))
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, app := range apps {
+		i, app := i, app
+		eg.Go(func() error {
+			p, err := repo.readAppPermissions(ctx, app)
+			m[i] = p
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (repo *Repository) readAppPermissions(ctx context.Context, app *App) (*AppPermissions, error) {
+	select {
+	case repo.Token <- struct{}{}: // acquire token
+		defer func() {
+			<-repo.Token
+		}()
+	case <-ctx.Done(): // cancelled
+		return nil, errors.New("canceled")
+	}
+
+	p := &AppPermissions{App: app}
+	var err error
+	if p.AppACL, err = repo.ReadAppACL(app.AppID); err != nil {
+		return nil, errors.Wrapf(err, "app %d: read app acl failed", app.AppID)
+	}
+	if p.Record, err = repo.ReadRecordACL(app.AppID); err != nil {
+		return nil, errors.Wrapf(err, "app %d: read record acl failed", app.AppID)
+	}
+	if p.Field, err = repo.ReadFieldACL(app.AppID); err != nil {
+		return nil, errors.Wrapf(err, "app %d: read field acl failed", app.AppID)
+	}
+	return p, nil
+}
+
+// 権限の範囲
+const (
+	PermissionScopeApp    = "APP"
+	PermissionScopeRecord = "RECORD"
+	PermissionScopeField  = "FIELD"
+)
+
+// permissionHeader は WriteCSV の列
+var permissionHeader = []string{"app", "appName", "scope", "target", "priority", "entityType", "entityCode", "includeSubs", "permissions"}
+
+// WriteCSV はアクセス権の設定を 1 行に 1 つの対象で書き出す
+// target はレコードのアクセス権では条件、フィールドのアクセス権ではフィールドコード
+// permissions は許可された権限を | で区切った値
+func (m PermissionMatrix) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(permissionHeader); err != nil {
+		return err
+	}
+
+	for _, p := range m {
+		row := func(scope, target string, priority int, e Entity, includeSubs bool, perms ...string) error {
+			return cw.Write([]string{
+				strconv.Itoa(p.App.AppID),
+				p.App.Name,
+				scope,
+				target,
+				strconv.Itoa(priority),
+				e.Type,
+				e.Code,
+				strconv.FormatBool(includeSubs),
+				strings.Join(perms, "|"),
+			})
+		}
+
+		for i, r := range p.AppACL {
+			err := row(PermissionScopeApp, "", i+1, r.Entity, r.IncludeSubs, allowed(
+				"appEditable", r.AppEditable,
+				"recordViewable", r.RecordViewable,
+				"recordAddable", r.RecordAddable,
+				"recordEditable", r.RecordEditable,
+				"recordDeletable", r.RecordDeletable,
+				"recordImportable", r.RecordImportable,
+				"recordExportable", r.RecordExportable,
+			)...)
+			if err != nil {
+				return err
+			}
+		}
+		for i, r := range p.Record {
+			for _, e := range r.Entities {
+				err := row(PermissionScopeRecord, r.FilterCond, i+1, e.Entity, e.IncludeSubs, allowed(
+					"viewable", e.Viewable,
+					"editable", e.Editable,
+					"deletable", e.Deletable,
+				)...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		for _, r := range p.Field {
+			for i, e := range r.Entities {
+				err := row(PermissionScopeField, r.Code, i+1, e.Entity, e.IncludeSubs, e.Accessibility)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// allowed は名前と値の組から値が true の名前を返す
+func allowed(pairs ...interface{}) []string {
+	var names []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if ok, _ := pairs[i+1].(bool); ok {
+			names = append(names, pairs[i].(string))
+		}
+	}
+	return names
+}
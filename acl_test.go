@@ -0,0 +1,94 @@
+package kintone
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateRecordACL(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		var body struct {
+			IDs []string `json:"ids"`
+		}
+		json.Unmarshal(req.Body, &body)
+
+		rights := make([]string, len(body.IDs))
+		for i, id := range body.IDs {
+			rights[i] = fmt.Sprintf(`{
+				"id": "%s",
+				"record": {"viewable": true, "editable": false, "deletable": false},
+				"fields": {"金額": {"viewable": true, "editable": false}}
+			}`, id)
+		}
+		return []byte(`{"rights": [` + strings.Join(rights, ",") + `]}`), nil
+	})
+
+	ids := make([]string, 150)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i + 1)
+	}
+	ps, err := repo.EvaluateRecordACL(nil, 3, ids)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(ps) != 150 || ps[0].ID != "1" || ps[149].ID != "150" {
+		t.Errorf("unexpected permissions: %d", len(ps))
+		return
+	}
+	if p := ps[0]; !p.Record.Viewable || p.Record.Editable || !p.Fields["金額"].Viewable {
+		t.Errorf("unexpected permission: %#v", p)
+	}
+	if len(c.requests) != 2 || c.requests[0].Path != APIEndpointRecordsACLEvaluate {
+		t.Errorf("unexpected requests: %d", len(c.requests))
+	}
+}
+
+func TestPermissionMatrix(t *testing.T) {
+	repo, _ := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		switch req.Path {
+		case APIEndpointApp:
+			return []byte(`{"appId": "3", "name": "案件"}`), nil
+		case APIEndpointAppACL:
+			return []byte(`{"rights": [{
+				"entity": {"type": "CREATOR", "code": null},
+				"appEditable": true, "recordViewable": true, "recordAddable": true
+			}]}`), nil
+		case APIEndpointRecordACL:
+			return []byte(`{"rights": [{
+				"filterCond": "金額 > 100",
+				"entities": [{"entity": {"type": "GROUP", "code": "sales"}, "viewable": true, "editable": true}]
+			}]}`), nil
+		case APIEndpointFieldACL:
+			return []byte(`{"rights": [{
+				"code": "金額",
+				"entities": [{"accessibility": "READ", "entity": {"type": "USER", "code": "sato"}, "includeSubs": false}]
+			}]}`), nil
+		}
+		return nil, fmt.Errorf("unexpected path: %s", req.Path)
+	})
+
+	m, err := repo.ReadPermissionMatrix(nil, 3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteCSV(&buf); err != nil {
+		t.Error(err)
+		return
+	}
+	expected := strings.Join([]string{
+		"app,appName,scope,target,priority,entityType,entityCode,includeSubs,permissions",
+		"3,案件,APP,,1,CREATOR,,false,appEditable|recordViewable|recordAddable",
+		"3,案件,RECORD,金額 > 100,1,GROUP,sales,false,viewable|editable",
+		"3,案件,FIELD,金額,1,USER,sato,false,READ",
+	}, "\n") + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\nactual:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,130 @@
+package kintone
+
+import (
+	"encoding/json"
+)
+
+// appsLimit は一度に取得できるアプリの最大数
+const appsLimit = 100
+
+// AppQuery はアプリの検索条件。指定しない条件では絞り込まない
+type AppQuery struct {
+	IDs      []int
+	Codes    []string
+	Name     string // アプリ名の部分一致
+	SpaceIDs []int
+	Limit    int // 0 の場合は全てのアプリを返す
+	Offset   int
+}
+
+// ReadApp はアプリの情報を返す
+func (repo *Repository) ReadApp(appID int) (*App, error) {
+	data, err := repo.Client.get(APIEndpointApp, &Query{ID: appID})
+	if err != nil {
+		return nil, err
+	}
+
+	var app App
+	err = json.Unmarshal(data, &app)
+	if err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// ListApps は条件に一致するアプリを返す
+// 100 件を超える場合は繰り返し取得する
+func (repo *Repository) ListApps(q *AppQuery) ([]*App, error) {
+	if q == nil {
+		q = &AppQuery{}
+	}
+
+	var apps []*App
+	offset := q.Offset
+	for {
+		limit := appsLimit
+		if q.Limit > 0 && q.Limit-len(apps) < limit {
+			limit = q.Limit - len(apps)
+		}
+
+		body, err := json.Marshal(struct {
+			IDs      []int    `json:"ids,omitempty"`
+			Codes    []string `json:"codes,omitempty"`
+			Name     string   `json:"name,omitempty"`
+			SpaceIDs []int    `json:"spaceIds,omitempty"`
+			Limit    int      `json:"limit"`
+			Offset   int      `json:"offset"`
+		}{q.IDs, q.Codes, q.Name, q.SpaceIDs, limit, offset})
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := repo.Client.getWithBody(APIEndpointApps, body)
+		if err != nil {
+			return nil, err
+		}
+
+		raw := struct {
+			Apps []*App `json:"apps"`
+		}{}
+		err = json.Unmarshal(data, &raw)
+		if err != nil {
+			return nil, err
+		}
+
+		apps = append(apps, raw.Apps...)
+		offset += len(raw.Apps)
+
+		if len(raw.Apps) < limit || (q.Limit > 0 && len(apps) >= q.Limit) {
+			return apps, nil
+		}
+	}
+}
+
+// CreateApp はアプリを作成し、アプリ ID を返す
+// spaceID、threadID を指定した場合はスペースのスレッドに作成する。0 の場合はスペースに所属しない
+// 作成したアプリを使うには DeployApps で運用環境に反映する
+func (repo *Repository) CreateApp(name string, spaceID, threadID int) (int, error) {
+	body, err := json.Marshal(struct {
+		Name   string `json:"name"`
+		Space  int    `json:"space,omitempty"`
+		Thread int    `json:"thread,omitempty"`
+	}{name, spaceID, threadID})
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := repo.Client.post(APIEndpointPreviewApp, body)
+	if err != nil {
+		return 0, err
+	}
+
+	res := struct {
+		App int `json:"app,string"`
+	}{}
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return 0, err
+	}
+	return res.App, nil
+}
+
+// MoveApp はアプリをスペースに移動する
+// spaceID が 0 の場合はスペースに所属しないアプリにする
+func (repo *Repository) MoveApp(appID, spaceID int) error {
+	var space *int
+	if spaceID != 0 {
+		space = &spaceID
+	}
+
+	body, err := json.Marshal(struct {
+		App   int  `json:"app"`
+		Space *int `json:"space"`
+	}{appID, space})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Client.post(APIEndpointMoveApp, body)
+	return err
+}
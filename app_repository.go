@@ -0,0 +1,83 @@
+package kintone
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// AppRepository はアプリと構造体の型を固定した Repository
+//
+//	customers := kintone.NewAppRepository[Customer](repo, 10)
+//	cs, err := customers.Find(ctx, `name like "佐藤"`)
+//
+// 取得するフィールドは T の kintone タグから自動で絞り込む。
+type AppRepository[T any] struct {
+	Repository *Repository
+	AppID      int
+	Fields     []string
+}
+
+// NewAppRepository ...
+func NewAppRepository[T any](repo *Repository, appID int) *AppRepository[T] {
+	return &AppRepository[T]{
+		Repository: repo,
+		AppID:      appID,
+		Fields:     FieldCodes(new(T)),
+	}
+}
+
+func (r *AppRepository[T]) query(condition string) *Query {
+	return &Query{AppID: r.AppID, Condition: condition, Fields: r.Fields}
+}
+
+// Find は条件に一致するレコードを取得する
+func (r *AppRepository[T]) Find(ctx context.Context, condition string) ([]T, error) {
+	var out []T
+	err := r.Repository.ReadRecordsInto(ctx, r.query(condition), &out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Get はレコード ID を指定してレコードを取得する
+// id が数字でない場合はエラーを返す
+func (r *AppRepository[T]) Get(ctx context.Context, id string) (T, error) {
+	var v T
+
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return v, fmt.Errorf("kintone: invalid record id %q", id)
+	}
+
+	ts, err := r.Find(ctx, fmt.Sprintf(`$id = "%d"`, n))
+	if err != nil {
+		return v, err
+	}
+	if len(ts) == 0 {
+		return v, ErrNotFound
+	}
+	return ts[0], nil
+}
+
+// Insert はレコードを追加し、追加したレコードの ID を返す
+func (r *AppRepository[T]) Insert(ctx context.Context, vs ...T) ([]string, error) {
+	return r.Repository.AddRecordsFrom(ctx, r.AppID, vs)
+}
+
+// Update はレコード ID（$id タグ）をキーにレコードを更新する
+func (r *AppRepository[T]) Update(ctx context.Context, vs ...T) error {
+	return r.Repository.UpdateRecordsFrom(ctx, r.AppID, "", vs)
+}
+
+// Upsert は updateKey をキーにレコードを追加・更新する
+// updateKey が空の場合はレコード ID をキーにする
+func (r *AppRepository[T]) Upsert(ctx context.Context, updateKey string, vs ...T) error {
+	return r.Repository.UpsertRecordsFrom(ctx, r.AppID, updateKey, vs)
+}
+
+// Delete はレコード ID を指定してレコードを削除する
+func (r *AppRepository[T]) Delete(ctx context.Context, ids ...string) error {
+	return r.Repository.DeleteRecords(ctx, r.AppID, ids)
+}
@@ -0,0 +1,115 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testTask struct {
+	ID    string `kintone:"$id"`
+	Title string `kintone:"title"`
+	Point int    `kintone:"point"`
+}
+
+func TestAppRepositoryFields(t *testing.T) {
+	repo, _ := newFakeRepository(nil)
+	tasks := NewAppRepository[testTask](repo, 10)
+
+	expected := []string{"$id", "title", "point"}
+	if !reflect.DeepEqual(expected, tasks.Fields) {
+		t.Errorf("expected: %v, actual: %v", expected, tasks.Fields)
+	}
+}
+
+func TestAppRepositoryFind(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Query.TotalCount && req.Query.limit == 0 {
+			return []byte(`{"totalCount": "1"}`), nil
+		}
+		return []byte(`{
+			"records": [
+				{
+					"$id": {"type": "__ID__", "value": "3"},
+					"title": {"type": "SINGLE_LINE_TEXT", "value": "hello"},
+					"point": {"type": "NUMBER", "value": "5"}
+				}
+			]
+		}`), nil
+	})
+	tasks := NewAppRepository[testTask](repo, 10)
+
+	v, err := tasks.Get(context.Background(), "3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := testTask{ID: "3", Title: "hello", Point: 5}
+	if v != expected {
+		t.Errorf("expected: %v, actual: %v", expected, v)
+	}
+
+	q := c.requests[len(c.requests)-1].Query
+	if q.AppID != 10 || q.Condition != `$id = "3"` || !reflect.DeepEqual(q.Fields, tasks.Fields) {
+		t.Errorf("unexpected query: %#v", q)
+	}
+
+	n := len(c.requests)
+	if _, err := tasks.Get(context.Background(), `1" or $id > "0`); err == nil {
+		t.Error("expected error for invalid id")
+	}
+	if len(c.requests) != n {
+		t.Errorf("unexpected requests: %d", len(c.requests)-n)
+	}
+}
+
+func TestAppRepositoryUpdate(t *testing.T) {
+	repo, c := newFakeRepository(nil)
+	tasks := NewAppRepository[testTask](repo, 10)
+
+	err := tasks.Update(context.Background(), testTask{ID: "3", Title: "hello", Point: 5})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(c.requests) != 1 || c.requests[0].Method != "PUT" || c.requests[0].Path != APIEndpointRecords {
+		t.Errorf("unexpected requests: %v", c.requests)
+		return
+	}
+
+	expected := []byte(`{"app":10,"records":[{"id":"3","record":{"title":{"value":"hello"},"point":{"value":"5"}}}]}`)
+	if !jsonEqual(expected, c.requests[0].Body) {
+		t.Errorf("expected: %s, actual: %s", expected, c.requests[0].Body)
+	}
+}
+
+func TestAppRepositoryInsert(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"ids": ["1", "2"], "revisions": ["1", "1"]}`), nil
+	})
+	tasks := NewAppRepository[testTask](repo, 10)
+
+	ids, err := tasks.Insert(context.Background(), testTask{Title: "a"}, testTask{Title: "b"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(ids) != 2 {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+
+	var body struct {
+		Records []map[string]json.RawMessage `json:"records"`
+	}
+	err = json.Unmarshal(c.requests[0].Body, &body)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(body.Records) != 2 {
+		t.Errorf("unexpected body: %s", c.requests[0].Body)
+	}
+}
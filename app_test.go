@@ -0,0 +1,113 @@
+package kintone
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadApp(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{
+			"appId": "3",
+			"code": "SALES",
+			"name": "案件",
+			"description": "",
+			"spaceId": "7",
+			"threadId": "8",
+			"createdAt": "2020-01-02T03:04:05.000Z",
+			"creator": {"code": "sato", "name": "佐藤"},
+			"modifiedAt": "2020-01-02T03:04:05.000Z",
+			"modifier": {"code": "sato", "name": "佐藤"}
+		}`), nil
+	})
+
+	app, err := repo.ReadApp(3)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if app.AppID != 3 || app.Code != "SALES" || app.SpaceID != 7 || app.ThreadID != 8 || app.Creator.Code != "sato" {
+		t.Errorf("unexpected app: %#v", app)
+	}
+	if req := c.requests[0]; req.Path != APIEndpointApp || req.Query.ID != 3 {
+		t.Errorf("unexpected request: %#v", req)
+	}
+}
+
+func TestListApps(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		var body struct {
+			Limit  int `json:"limit"`
+			Offset int `json:"offset"`
+		}
+		json.Unmarshal(req.Body, &body)
+
+		// 全部で 150 件のアプリ
+		n := body.Limit
+		if 150-body.Offset < n {
+			n = 150 - body.Offset
+		}
+		apps := make([]string, n)
+		for i := range apps {
+			apps[i] = fmt.Sprintf(`{"appId": "%d", "spaceId": null}`, body.Offset+i+1)
+		}
+		return []byte(`{"apps": [` + strings.Join(apps, ",") + `]}`), nil
+	})
+
+	apps, err := repo.ListApps(&AppQuery{SpaceIDs: []int{7}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(apps) != 150 || apps[149].AppID != 150 || apps[0].SpaceID != 0 {
+		t.Errorf("unexpected apps: %d", len(apps))
+	}
+	expected := []byte(`{"spaceIds": [7], "limit": 100, "offset": 100}`)
+	if len(c.requests) != 2 || !jsonEqual(expected, c.requests[1].Body) {
+		t.Errorf("unexpected requests: %d", len(c.requests))
+	}
+
+	apps, err = repo.ListApps(&AppQuery{Limit: 120, Offset: 10})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(apps) != 120 || apps[0].AppID != 11 {
+		t.Errorf("unexpected apps: %d", len(apps))
+	}
+	expected = []byte(`{"limit": 20, "offset": 110}`)
+	if last := c.requests[len(c.requests)-1]; !jsonEqual(expected, last.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, last.Body)
+	}
+}
+
+func TestCreateApp(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"app": "23", "revision": "1"}`), nil
+	})
+
+	id, err := repo.CreateApp("案件", 7, 8)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if id != 23 {
+		t.Errorf("expected: 23, actual: %d", id)
+	}
+
+	expected := []byte(`{"name": "案件", "space": 7, "thread": 8}`)
+	if req := c.requests[0]; req.Method != "POST" || req.Path != APIEndpointPreviewApp || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+
+	if err := repo.MoveApp(23, 0); err != nil {
+		t.Error(err)
+		return
+	}
+	expected = []byte(`{"app": 23, "space": null}`)
+	if req := c.requests[1]; req.Path != APIEndpointMoveApp || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+}
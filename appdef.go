@@ -0,0 +1,608 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AppDefinition はアプリの設定一式
+// ファイルに保存してバージョン管理し、ApplyApp で他のアプリに適用する
+// nil の項目は ApplyApp で変更しない
+// Renames には変更前のフィールドコードから変更後のフィールドコードへの対応を指定する
+type AppDefinition struct {
+	Settings      *AppSettings       `json:"settings,omitempty"`
+	Fields        FormFields         `json:"fields,omitempty"`
+	Layout        FormLayouts        `json:"layout,omitempty"`
+	Views         Views              `json:"views,omitempty"`
+	Process       *ProcessManagement `json:"process,omitempty"`
+	AppACL        AppACL             `json:"appAcl,omitempty"`
+	RecordACL     RecordACL          `json:"recordAcl,omitempty"`
+	FieldACL      FieldACL           `json:"fieldAcl,omitempty"`
+	Notifications *Notifications     `json:"notifications,omitempty"`
+	Renames       map[string]string  `json:"renames,omitempty"`
+}
+
+// DecodeAppDefinition は JSON のアプリの設定を読み込む
+func DecodeAppDefinition(r io.Reader) (*AppDefinition, error) {
+	var def AppDefinition
+	err := json.NewDecoder(r).Decode(&def)
+	if err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// EncodeAppDefinition はアプリの設定を差分が読みやすい JSON で書き込む
+func EncodeAppDefinition(w io.Writer, def *AppDefinition) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(def)
+}
+
+// ExportApp は運用環境のアプリの設定一式を返す
+// 一覧の ID はアプリごとに異なるため出力しない
+// ステータス、作業者、カテゴリーはプロセス管理、カテゴリーの設定のため、フィールドに含めない
+func (repo *Repository) ExportApp(appID int) (*AppDefinition, error) {
+	def, _, err := repo.exportApp(appID, false)
+	return def, err
+}
+
+// exportApp はアプリの設定一式を返す
+// preview が true の場合は運用環境に反映する前の設定と revision を返す
+func (repo *Repository) exportApp(appID int, preview bool) (*AppDefinition, string, error) {
+	path := func(p string) string {
+		if preview {
+			return strings.Replace(p, "/k/v1/", "/k/v1/preview/", 1)
+		}
+		return p
+	}
+
+	var def AppDefinition
+
+	def.Settings = &AppSettings{}
+	err := repo.readAppSetting(path(APIEndpointAppSettings), appID, def.Settings)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read app settings failed")
+	}
+
+	fields := struct {
+		Properties FormFields `json:"properties"`
+		Revision   string     `json:"revision"`
+	}{}
+	err = repo.readAppSetting(path(APIEndpointFormField), appID, &fields)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read form fields failed")
+	}
+	def.Fields = fields.Properties
+	for code, ff := range def.Fields {
+		if !isLayoutFieldType(ff.Type) {
+			delete(def.Fields, code)
+		}
+	}
+
+	layout := struct {
+		Layout FormLayouts `json:"layout"`
+	}{}
+	err = repo.readAppSetting(path(APIEndpointFormLayout), appID, &layout)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read form layout failed")
+	}
+	def.Layout = layout.Layout
+
+	views := struct {
+		Views Views `json:"views"`
+	}{}
+	err = repo.readAppSetting(path(APIEndpointViews), appID, &views)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read views failed")
+	}
+	def.Views = views.Views
+	for _, v := range def.Views {
+		v.ID = ""
+	}
+
+	def.Process = &ProcessManagement{}
+	err = repo.readAppSetting(path(APIEndpointProcess), appID, def.Process)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read process management failed")
+	}
+
+	appACL := struct {
+		Rights AppACL `json:"rights"`
+	}{}
+	err = repo.readAppSetting(path(APIEndpointAppACL), appID, &appACL)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read app acl failed")
+	}
+	def.AppACL = appACL.Rights
+
+	recordACL := struct {
+		Rights RecordACL `json:"rights"`
+	}{}
+	err = repo.readAppSetting(path(APIEndpointRecordACL), appID, &recordACL)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read record acl failed")
+	}
+	def.RecordACL = recordACL.Rights
+
+	fieldACL := struct {
+		Rights FieldACL `json:"rights"`
+	}{}
+	err = repo.readAppSetting(path(APIEndpointFieldACL), appID, &fieldACL)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read field acl failed")
+	}
+	def.FieldACL = fieldACL.Rights
+
+	n := &Notifications{General: &GeneralNotifications{}, Reminder: &ReminderNotifications{}}
+	perRecord := struct {
+		Notifications []*PerRecordNotification `json:"notifications"`
+	}{}
+	err = repo.readAppSetting(path(APIEndpointGeneralNotifications), appID, n.General)
+	if err == nil {
+		err = repo.readAppSetting(path(APIEndpointPerRecordNotifications), appID, &perRecord)
+	}
+	if err == nil {
+		err = repo.readAppSetting(path(APIEndpointReminderNotifications), appID, n.Reminder)
+	}
+	if err != nil {
+		return nil, "", errors.Wrap(err, "read notifications failed")
+	}
+	n.PerRecord = perRecord.Notifications
+	def.Notifications = n
+
+	return &def, fields.Revision, nil
+}
+
+// 設定の変更の対象
+const (
+	AppTargetSettings               = "settings"
+	AppTargetField                  = "field"
+	AppTargetLayout                 = "layout"
+	AppTargetView                   = "view"
+	AppTargetProcess                = "process"
+	AppTargetAppACL                 = "appAcl"
+	AppTargetRecordACL              = "recordAcl"
+	AppTargetFieldACL               = "fieldAcl"
+	AppTargetGeneralNotifications   = "generalNotifications"
+	AppTargetPerRecordNotifications = "perRecordNotifications"
+	AppTargetReminderNotifications  = "reminderNotifications"
+)
+
+// 設定の変更の種類
+const (
+	AppChangeAdd     = "add"
+	AppChangeUpdate  = "update"
+	AppChangeDelete  = "delete"
+	AppChangeReplace = "replace" // フィールドタイプの変更。データが失われるため ApplyApp では適用しない
+)
+
+// AppChange はアプリの設定の変更
+type AppChange struct {
+	Target string
+	Name   string // フィールドコード、一覧名
+	Action string
+	Old    interface{}
+	New    interface{}
+}
+
+func (c *AppChange) String() string {
+	mark := map[string]string{
+		AppChangeAdd:     "+",
+		AppChangeUpdate:  "~",
+		AppChangeDelete:  "-",
+		AppChangeReplace: "!",
+	}[c.Action]
+
+	if c.Name == "" {
+		return fmt.Sprintf("%s %s", mark, c.Target)
+	}
+	return fmt.Sprintf("%s %s %s", mark, c.Target, c.Name)
+}
+
+// AppPlan はアプリに適用する変更
+type AppPlan []*AppChange
+
+func (p AppPlan) String() string {
+	lines := make([]string, len(p))
+	for i, c := range p {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiffApp は current を desired にするための変更を返す
+// desired の nil の項目は比較しない
+// フィールドはフィールドコードで対応させる。desired.Renames にあるフィールドと、
+// 削除と追加になるフィールドのうちラベルとフィールドタイプが一対一で一致するものはフィールドコードの変更として扱う
+// レコード番号、作成者などはフィールドタイプで対応させ、フィールドコードの変更を更新として扱う
+func DiffApp(current, desired *AppDefinition) AppPlan {
+	var plan AppPlan
+	add := func(target, name, action string, old, new interface{}) {
+		plan = append(plan, &AppChange{target, name, action, old, new})
+	}
+	compare := func(target string, old, new interface{}) {
+		if !reflect.ValueOf(new).IsNil() && !sameSetting(old, new) {
+			add(target, "", AppChangeUpdate, old, new)
+		}
+	}
+
+	compare(AppTargetSettings, current.Settings, desired.Settings)
+
+	if desired.Fields != nil {
+		plan = append(plan, diffFormFields(current.Fields, desired.Fields, desired.Renames)...)
+	}
+
+	compare(AppTargetLayout, current.Layout, desired.Layout)
+
+	if desired.Views != nil {
+		for _, name := range unionKeys(current.Views, desired.Views) {
+			old, new := current.Views[name], desired.Views[name]
+			switch {
+			case old == nil:
+				add(AppTargetView, name, AppChangeAdd, nil, new)
+			case new == nil:
+				add(AppTargetView, name, AppChangeDelete, old, nil)
+			case !sameSetting(old, new):
+				add(AppTargetView, name, AppChangeUpdate, old, new)
+			}
+		}
+	}
+
+	compare(AppTargetProcess, current.Process, desired.Process)
+	compare(AppTargetAppACL, current.AppACL, desired.AppACL)
+	compare(AppTargetRecordACL, current.RecordACL, desired.RecordACL)
+	compare(AppTargetFieldACL, current.FieldACL, desired.FieldACL)
+
+	if desired.Notifications != nil {
+		n := current.Notifications
+		if n == nil {
+			n = &Notifications{}
+		}
+		compare(AppTargetGeneralNotifications, n.General, desired.Notifications.General)
+		compare(AppTargetPerRecordNotifications, n.PerRecord, desired.Notifications.PerRecord)
+		compare(AppTargetReminderNotifications, n.Reminder, desired.Notifications.Reminder)
+	}
+
+	return plan
+}
+
+func diffFormFields(current, desired FormFields, renames map[string]string) []*AppChange {
+	currentByKey := formFieldsByKey(current)
+	desiredByKey := formFieldsByKey(desired)
+
+	// 変更後のフィールドを変更前のフィールドコードで対応させる
+	for oldCode, newCode := range renames {
+		if ff, ok := desiredByKey[newCode]; ok && currentByKey[oldCode] != nil && desiredByKey[oldCode] == nil {
+			delete(desiredByKey, newCode)
+			desiredByKey[oldCode] = ff
+		}
+	}
+
+	var changes []*AppChange
+	for _, key := range unionKeys(currentByKey, desiredByKey) {
+		old, new := currentByKey[key], desiredByKey[key]
+		switch {
+		case old == nil:
+			changes = append(changes, &AppChange{AppTargetField, new.Code, AppChangeAdd, nil, new})
+		case new == nil:
+			changes = append(changes, &AppChange{AppTargetField, old.Code, AppChangeDelete, old, nil})
+		case old.Type != new.Type:
+			changes = append(changes, &AppChange{AppTargetField, new.Code, AppChangeReplace, old, new})
+		case !sameSetting(old, new):
+			changes = append(changes, &AppChange{AppTargetField, new.Code, AppChangeUpdate, old, new})
+		}
+	}
+	return detectRenames(changes)
+}
+
+// detectRenames はラベルとフィールドタイプが一対一で一致する削除と追加をフィールドコードの変更にする
+func detectRenames(changes []*AppChange) []*AppChange {
+	key := func(ff *FormField) string {
+		return ff.Type + "\x00" + ff.Label
+	}
+	added := make(map[string][]*AppChange)
+	deleted := make(map[string][]*AppChange)
+	for _, c := range changes {
+		switch c.Action {
+		case AppChangeAdd:
+			k := key(c.New.(*FormField))
+			added[k] = append(added[k], c)
+		case AppChangeDelete:
+			k := key(c.Old.(*FormField))
+			deleted[k] = append(deleted[k], c)
+		}
+	}
+
+	renamed := make(map[*AppChange]bool)
+	for k, as := range added {
+		ds := deleted[k]
+		if len(as) != 1 || len(ds) != 1 {
+			continue
+		}
+		as[0].Action, as[0].Old = AppChangeUpdate, ds[0].Old
+		renamed[ds[0]] = true
+	}
+
+	result := changes[:0]
+	for _, c := range changes {
+		if !renamed[c] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// formFieldsByKey はフィールドコード、またはレコード番号などのフィールドタイプごとのフィールドを返す
+func formFieldsByKey(fs FormFields) map[string]*FormField {
+	m := make(map[string]*FormField, len(fs))
+	for code, ff := range fs {
+		if ff == nil || !isLayoutFieldType(ff.Type) {
+			continue
+		}
+		key := code
+		if isSystemFieldType(ff.Type) {
+			// フィールドコードに ":" は使えないため重複しない
+			key = "type:" + ff.Type
+		}
+		m[key] = ff
+	}
+	return m
+}
+
+// unionKeys は m1, m2 のキーを並べて返す
+func unionKeys(m1, m2 interface{}) []string {
+	seen := make(map[string]bool)
+	for _, m := range []interface{}{m1, m2} {
+		for _, k := range reflect.ValueOf(m).MapKeys() {
+			seen[k.String()] = true
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sameSetting は JSON に変換した設定が同じかどうかを返す
+// null、空の文字列、配列、オブジェクトは省略したものとして比較する
+func sameSetting(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeSetting(a), normalizeSetting(b))
+}
+
+func normalizeSetting(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var raw interface{}
+	json.Unmarshal(data, &raw)
+	return compactSetting(raw)
+}
+
+func compactSetting(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if e = compactSetting(e); e == nil {
+				delete(v, k)
+			} else {
+				v[k] = e
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for i, e := range v {
+			v[i] = compactSetting(e)
+		}
+	case string:
+		if v == "" {
+			return nil
+		}
+	}
+	return v
+}
+
+// PlanApp はアプリに def を適用するための変更を返す
+// 運用環境に反映する前の設定と比較する
+func (repo *Repository) PlanApp(appID int, def *AppDefinition) (AppPlan, error) {
+	current, _, err := repo.exportApp(appID, true)
+	if err != nil {
+		return nil, err
+	}
+	return DiffApp(current, def), nil
+}
+
+// ApplyApp はアプリの設定を def にし、運用環境に反映する。適用した変更を返す
+// フィールドタイプの変更がある場合と、allowDelete が false でフィールドの削除がある場合は何も変更せずにエラーを返す
+// 変更は運用環境に反映する前の設定と比較し、比較した時点の revision で適用する
+// 適用中にエラーが発生した場合は、運用環境に反映する前の設定の変更を全て取り消す
+func (repo *Repository) ApplyApp(ctx context.Context, appID int, def *AppDefinition, allowDelete bool) (AppPlan, error) {
+	current, revision, err := repo.exportApp(appID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := DiffApp(current, def)
+	if len(plan) == 0 {
+		return nil, nil
+	}
+	for _, c := range plan {
+		if c.Action == AppChangeReplace {
+			return plan, errors.Errorf("field %s cannot be changed from %s to %s", c.Name, c.Old.(*FormField).Type, c.New.(*FormField).Type)
+		}
+		if !allowDelete && c.Target == AppTargetField && hasFieldDeletion(c) {
+			// フィールドを削除するとレコードの値が失われる
+			return plan, errors.Errorf("field %s cannot be deleted without allowDelete", c.Name)
+		}
+	}
+
+	apps := []*DeployApp{{App: appID}}
+	err = repo.applyPlan(appID, def, plan, revision)
+	if err != nil {
+		if rerr := repo.DeployApps(ctx, apps, true); rerr != nil {
+			return plan, errors.Wrapf(err, "revert failed: %v", rerr)
+		}
+		return plan, err
+	}
+
+	err = repo.DeployApps(ctx, apps, false)
+	if err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+func fieldTypeOfChange(c *AppChange) string {
+	if ff, ok := c.New.(*FormField); ok {
+		return ff.Type
+	}
+	return c.Old.(*FormField).Type
+}
+
+// hasFieldDeletion はフィールドの変更がフィールドの削除を含むかどうかを返す
+// テーブルの更新で無くなるテーブル内のフィールドも削除として扱う
+func hasFieldDeletion(c *AppChange) bool {
+	switch c.Action {
+	case AppChangeDelete:
+		return !isSystemFieldType(c.Old.(*FormField).Type)
+	case AppChangeUpdate:
+		old, new := c.Old.(*FormField), c.New.(*FormField)
+		for code := range old.Fields {
+			if _, ok := new.Fields[code]; !ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (repo *Repository) applyPlan(appID int, def *AppDefinition, plan AppPlan, revision string) error {
+	targets := make(map[string]bool)
+	var deleted []string
+	added := make(FormFields)
+	updated := make(FormFields)
+
+	for _, c := range plan {
+		targets[c.Target] = true
+		if c.Target != AppTargetField {
+			continue
+		}
+		if c.Action != AppChangeUpdate && isSystemFieldType(fieldTypeOfChange(c)) {
+			// レコード番号、作成者などは追加・削除できない
+			continue
+		}
+		switch c.Action {
+		case AppChangeAdd:
+			added[c.Name] = c.New.(*FormField)
+		case AppChangeDelete:
+			deleted = append(deleted, c.Old.(*FormField).Code)
+		case AppChangeUpdate:
+			old, new := c.Old.(*FormField), c.New.(*FormField)
+			// フィールドコードの変更は変更前のフィールドコードで指定する
+			updated[old.Code] = new
+			if old.Type == FieldTypeSubtable {
+				for code := range old.Fields {
+					if _, ok := new.Fields[code]; !ok {
+						deleted = append(deleted, code)
+					}
+				}
+			}
+		}
+	}
+
+	// 変更の度に revision を確認し、比較した後に他の変更があった場合はエラーにする
+	var err error
+
+	if targets[AppTargetSettings] {
+		if revision, err = repo.UpdateAppSettings(appID, def.Settings, revision); err != nil {
+			return errors.Wrap(err, "update app settings failed")
+		}
+	}
+
+	if len(deleted) > 0 {
+		sort.Strings(deleted)
+		if revision, err = repo.DeleteFormFields(appID, deleted, revision); err != nil {
+			return errors.Wrap(err, "delete form fields failed")
+		}
+	}
+	if len(updated) > 0 {
+		if revision, err = repo.UpdateFormFields(appID, updated, revision); err != nil {
+			return errors.Wrap(err, "update form fields failed")
+		}
+	}
+	if len(added) > 0 {
+		if revision, err = repo.AddFormFields(appID, added, revision); err != nil {
+			return errors.Wrap(err, "add form fields failed")
+		}
+	}
+
+	// 追加したフィールドはレイアウトの末尾に配置されるため、レイアウトも設定する
+	if def.Layout != nil && (targets[AppTargetLayout] || len(added) > 0) {
+		if revision, err = repo.UpdateFormLayout(appID, def.Layout, revision); err != nil {
+			return errors.Wrap(err, "update form layout failed")
+		}
+	}
+
+	if targets[AppTargetView] {
+		if revision, err = repo.UpdateViews(appID, def.Views, revision); err != nil {
+			return errors.Wrap(err, "update views failed")
+		}
+	}
+
+	if targets[AppTargetProcess] {
+		if revision, err = repo.UpdateProcessManagement(appID, def.Process, revision); err != nil {
+			return errors.Wrap(err, "update process management failed")
+		}
+	}
+
+	if targets[AppTargetAppACL] {
+		if revision, err = repo.UpdateAppACL(appID, def.AppACL, revision); err != nil {
+			return errors.Wrap(err, "update app acl failed")
+		}
+	}
+	if targets[AppTargetRecordACL] {
+		if revision, err = repo.UpdateRecordACL(appID, def.RecordACL, revision); err != nil {
+			return errors.Wrap(err, "update record acl failed")
+		}
+	}
+	if targets[AppTargetFieldACL] {
+		if revision, err = repo.UpdateFieldACL(appID, def.FieldACL, revision); err != nil {
+			return errors.Wrap(err, "update field acl failed")
+		}
+	}
+
+	if targets[AppTargetGeneralNotifications] {
+		if revision, err = repo.UpdateGeneralNotifications(appID, def.Notifications.General, revision); err != nil {
+			return errors.Wrap(err, "update general notifications failed")
+		}
+	}
+	if targets[AppTargetPerRecordNotifications] {
+		if revision, err = repo.UpdatePerRecordNotifications(appID, def.Notifications.PerRecord, revision); err != nil {
+			return errors.Wrap(err, "update per record notifications failed")
+		}
+	}
+	if targets[AppTargetReminderNotifications] {
+		if revision, err = repo.UpdateReminderNotifications(appID, def.Notifications.Reminder, revision); err != nil {
+			return errors.Wrap(err, "update reminder notifications failed")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package kintone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var testAppResponses = map[string]string{
+	APIEndpointAppSettings: `{"name": "案件", "description": "", "icon": {"type": "PRESET", "key": "APP72"}, "theme": "WHITE", "revision": "3"}`,
+	APIEndpointFormField: `{"properties": {
+		"名前": {"type": "SINGLE_LINE_TEXT", "code": "名前", "label": "名前"},
+		"旧": {"type": "NUMBER", "code": "旧", "label": "旧"},
+		"レコード番号": {"type": "RECORD_NUMBER", "code": "レコード番号", "label": "レコード番号"},
+		"ステータス": {"type": "STATUS", "code": "ステータス", "label": "ステータス", "enabled": false}
+	}, "revision": "3"}`,
+	APIEndpointFormLayout:             `{"layout": [{"type": "ROW", "fields": [{"type": "SINGLE_LINE_TEXT", "code": "名前", "size": {"width": "200"}}]}]}`,
+	APIEndpointViews:                  `{"views": {"一覧": {"id": "10", "type": "LIST", "name": "一覧", "index": "0", "fields": ["名前"], "filterCond": "", "sort": "$id desc"}}}`,
+	APIEndpointProcess:                `{"enable": false, "states": null, "actions": null}`,
+	APIEndpointAppACL:                 `{"rights": [{"entity": {"type": "CREATOR", "code": null}, "appEditable": true, "recordViewable": true}]}`,
+	APIEndpointRecordACL:              `{"rights": []}`,
+	APIEndpointFieldACL:               `{"rights": []}`,
+	APIEndpointGeneralNotifications:   `{"notifications": [], "notifyToCommenter": false}`,
+	APIEndpointPerRecordNotifications: `{"notifications": []}`,
+	APIEndpointReminderNotifications:  `{"notifications": [], "timezone": "Asia/Tokyo"}`,
+	APIEndpointPreviewDeploy:          `{"apps": [{"app": "1", "status": "SUCCESS"}]}`,
+}
+
+// testAppResponse は path のレスポンスを返す。運用環境に反映する前の設定も同じ
+func testAppResponse(path string) string {
+	if res, ok := testAppResponses[path]; ok {
+		return res
+	}
+	return testAppResponses[strings.Replace(path, "/preview/", "/", 1)]
+}
+
+func newFakeAppRepository() (*Repository, *fakeClient) {
+	return newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Method == "GET" {
+			return []byte(testAppResponse(req.Path)), nil
+		}
+		var body struct {
+			Revision string `json:"revision"`
+		}
+		json.Unmarshal(req.Body, &body)
+		if body.Revision == "" {
+			return []byte(`{"revision": "4"}`), nil
+		}
+		// 送られた revision の次の revision を返す
+		var n int
+		fmt.Sscan(body.Revision, &n)
+		return []byte(fmt.Sprintf(`{"revision": "%d"}`, n+1)), nil
+	})
+}
+
+func TestAppDefinition(t *testing.T) {
+	repo, c := newFakeAppRepository()
+
+	current, err := repo.ExportApp(1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := current.Fields["ステータス"]; ok {
+		t.Error("status should not be exported")
+	}
+	if current.Views["一覧"].ID != "" {
+		t.Error("view id should not be exported")
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeAppDefinition(&buf, current); err != nil {
+		t.Error(err)
+		return
+	}
+	desired, err := DecodeAppDefinition(&buf)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if plan := DiffApp(current, desired); len(plan) != 0 {
+		t.Errorf("unexpected plan: %s", plan)
+	}
+
+	desired.Fields["No"] = desired.Fields["レコード番号"]
+	desired.Fields["No"].Code = "No"
+	delete(desired.Fields, "レコード番号")
+	desired.Fields["名前"].Label = "氏名"
+	desired.Fields["メモ"] = &FormField{Code: "メモ", Label: "メモ", Type: FieldTypeMultiLineText}
+	delete(desired.Fields, "旧")
+	desired.Layout = append(desired.Layout, LayoutRow(&FormLayoutField{Type: FieldTypeMultiLineText, Code: "メモ"}))
+	desired.Views["一覧"].Sort = "$id asc"
+	desired.AppACL = nil
+
+	if _, err := repo.ApplyApp(context.Background(), 1, desired, false); err == nil {
+		t.Error("deletion should be refused")
+	}
+	for _, req := range c.requests {
+		if req.Method != "GET" {
+			t.Errorf("app should not be changed: %s %s", req.Method, req.Path)
+		}
+	}
+	c.requests = nil
+
+	plan, err := repo.ApplyApp(context.Background(), 1, desired, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := strings.Join([]string{
+		"~ field No",
+		"+ field メモ",
+		"~ field 名前",
+		"- field 旧",
+		"~ layout",
+		"~ view 一覧",
+	}, "\n")
+	if plan.String() != expected {
+		t.Errorf("expected: %s, actual: %s", expected, plan)
+	}
+
+	var writes []string
+	revision := 3
+	for _, req := range c.requests {
+		if req.Method != "GET" {
+			writes = append(writes, req.Method+" "+req.Path)
+		}
+		if req.Method == "GET" || req.Path == APIEndpointPreviewDeploy {
+			continue
+		}
+		var body struct {
+			Revision string `json:"revision"`
+		}
+		json.Unmarshal(req.Body, &body)
+		if expected := fmt.Sprint(revision); body.Revision != expected {
+			t.Errorf("%s %s: expected revision: %s, actual: %s", req.Method, req.Path, expected, body.Revision)
+		}
+		revision++
+	}
+	expectedWrites := []string{
+		"DELETE " + APIEndpointPreviewFormField,
+		"PUT " + APIEndpointPreviewFormField,
+		"POST " + APIEndpointPreviewFormField,
+		"PUT " + APIEndpointPreviewFormLayout,
+		"PUT " + APIEndpointPreviewViews,
+		"POST " + APIEndpointPreviewDeploy,
+	}
+	if strings.Join(writes, "\n") != strings.Join(expectedWrites, "\n") {
+		t.Errorf("expected: %v, actual: %v", expectedWrites, writes)
+	}
+
+	for _, req := range c.requests {
+		if req.Method == "PUT" && req.Path == APIEndpointPreviewFormField {
+			var body struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+			}
+			json.Unmarshal(req.Body, &body)
+			if body.Properties["レコード番号"]["code"] != "No" || body.Properties["名前"]["label"] != "氏名" {
+				t.Errorf("unexpected body: %s", req.Body)
+			}
+		}
+	}
+}
+
+func TestApplyAppReplace(t *testing.T) {
+	repo, c := newFakeAppRepository()
+
+	desired := &AppDefinition{Fields: FormFields{
+		"名前": {Code: "名前", Label: "名前", Type: FieldTypeSingleLineText},
+		"旧":  {Code: "旧", Label: "旧", Type: FieldTypeSingleLineText},
+	}}
+
+	_, err := repo.ApplyApp(context.Background(), 1, desired, true)
+	if err == nil {
+		t.Error("expected error")
+	}
+	for _, req := range c.requests {
+		if req.Method != "GET" {
+			t.Errorf("app should not be changed: %s %s", req.Method, req.Path)
+		}
+	}
+}
+
+func TestDiffAppRename(t *testing.T) {
+	current := &AppDefinition{Fields: FormFields{
+		"名前": {Code: "名前", Label: "名前", Type: FieldTypeSingleLineText},
+		"旧":  {Code: "旧", Label: "金額", Type: FieldTypeNumber},
+		"備考": {Code: "備考", Label: "備考", Type: FieldTypeMultiLineText},
+	}}
+	desired := &AppDefinition{
+		Fields: FormFields{
+			"氏名": {Code: "氏名", Label: "名前", Type: FieldTypeSingleLineText},
+			"金額": {Code: "金額", Label: "金額", Type: FieldTypeNumber},
+			"メモ": {Code: "メモ", Label: "メモ", Type: FieldTypeMultiLineText},
+		},
+		Renames: map[string]string{"備考": "メモ"},
+	}
+
+	plan := DiffApp(current, desired)
+	expected := strings.Join([]string{
+		"~ field メモ",
+		"~ field 氏名",
+		"~ field 金額",
+	}, "\n")
+	if plan.String() != expected {
+		t.Errorf("expected: %s, actual: %s", expected, plan)
+	}
+	for _, c := range plan {
+		if c.Old == nil {
+			t.Errorf("%s should be renamed", c.Name)
+		}
+	}
+
+	// ラベルとフィールドタイプが一致するフィールドが複数ある場合は対応させない
+	desired = &AppDefinition{Fields: FormFields{
+		"名前":  current.Fields["名前"],
+		"備考":  current.Fields["備考"],
+		"金額":  {Code: "金額", Label: "金額", Type: FieldTypeNumber},
+		"金額2": {Code: "金額2", Label: "金額", Type: FieldTypeNumber},
+	}}
+	expected = strings.Join([]string{
+		"- field 旧",
+		"+ field 金額",
+		"+ field 金額2",
+	}, "\n")
+	if plan := DiffApp(current, desired); plan.String() != expected {
+		t.Errorf("expected: %s, actual: %s", expected, plan)
+	}
+}
+
+func TestApplyAppRename(t *testing.T) {
+	repo, c := newFakeAppRepository()
+
+	desired := &AppDefinition{Fields: FormFields{
+		"名前": {Code: "名前", Label: "名前", Type: FieldTypeSingleLineText},
+		"金額": {Code: "金額", Label: "旧", Type: FieldTypeNumber},
+	}}
+
+	// フィールドコードの変更は削除ではない
+	if _, err := repo.ApplyApp(context.Background(), 1, desired, false); err != nil {
+		t.Error(err)
+		return
+	}
+	for _, req := range c.requests {
+		if req.Method == "DELETE" || req.Method == "POST" && req.Path == APIEndpointPreviewFormField {
+			t.Errorf("unexpected request: %s %s", req.Method, req.Path)
+		}
+		if req.Method == "PUT" && req.Path == APIEndpointPreviewFormField {
+			var body struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+			}
+			json.Unmarshal(req.Body, &body)
+			if body.Properties["旧"]["code"] != "金額" {
+				t.Errorf("unexpected body: %s", req.Body)
+			}
+		}
+	}
+}
+
+func TestApplyAppPreview(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Method == "GET" {
+			if req.Path == APIEndpointPreviewFormField {
+				// 運用環境に反映していないフィールドがある
+				return []byte(`{"properties": {
+					"名前": {"type": "SINGLE_LINE_TEXT", "code": "名前", "label": "名前"},
+					"メモ": {"type": "MULTI_LINE_TEXT", "code": "メモ", "label": "メモ"}
+				}, "revision": "8"}`), nil
+			}
+			return []byte(testAppResponse(req.Path)), nil
+		}
+		return []byte(`{"revision": "9"}`), nil
+	})
+
+	desired := &AppDefinition{Fields: FormFields{
+		"名前": {Code: "名前", Label: "氏名", Type: FieldTypeSingleLineText},
+		"メモ": {Code: "メモ", Label: "メモ", Type: FieldTypeMultiLineText},
+	}}
+	plan, err := repo.ApplyApp(context.Background(), 1, desired, false)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if expected := "~ field 名前"; plan.String() != expected {
+		t.Errorf("expected: %s, actual: %s", expected, plan)
+	}
+
+	for _, req := range c.requests {
+		if req.Method == "GET" && !strings.Contains(req.Path, "/preview/") {
+			t.Errorf("production settings should not be read: %s", req.Path)
+		}
+		if req.Method == "PUT" && req.Path == APIEndpointPreviewFormField {
+			var body struct {
+				Revision string `json:"revision"`
+			}
+			json.Unmarshal(req.Body, &body)
+			if body.Revision != "8" {
+				t.Errorf("expected revision: 8, actual: %s", body.Revision)
+			}
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// AttachmentManifestName は ExportAttachments が書き出すマニフェストのファイル名
+const AttachmentManifestName = "manifest.json"
+
+// AttachmentManifest は ExportAttachments で書き出した添付ファイルの一覧
+//
+// ディレクトリは次の構成になる
+//
+//	manifest.json
+//	<キー>/<フィールドコード>/<番号>_<ファイル名>
+//	<キー>/<テーブルのフィールドコード>/<行番号>/<フィールドコード>/<番号>_<ファイル名>
+type AttachmentManifest struct {
+	AppID       int           `json:"app"`
+	KeyField    string        `json:"keyField"` // レコードを識別するフィールドコード。$id の場合はレコード ID
+	Attachments []*Attachment `json:"attachments"`
+}
+
+// Attachment はレコードの添付ファイル
+type Attachment struct {
+	Key         string `json:"key"`             // KeyField の値
+	Table       string `json:"table,omitempty"` // テーブル内のファイルの場合はテーブルのフィールドコード
+	Row         int    `json:"row"`             // テーブル内のファイルの場合は行番号
+	Field       string `json:"field"`
+	Index       int    `json:"index"` // フィールド内の順番
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Size        uint64 `json:"size,string"`
+	Path        string `json:"path"` // ディレクトリからの相対パス
+}
+
+// ExportAttachments はクエリに一致するレコードのファイルフィールドの添付ファイルを dir にダウンロードし、マニフェストを返す
+// テーブル内のファイルフィールドも対象にする
+// keyField はインポート先でレコードを探すためのフィールドコード。空の場合はレコード ID を使う
+func (repo *Repository) ExportAttachments(ctx context.Context, q *Query, dir string, keyField string) (*AttachmentManifest, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if keyField == "" {
+		keyField = fieldCodeID
+	}
+
+	rs, err := repo.ReadRecords(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "read records failed")
+	}
+
+	m := &AttachmentManifest{AppID: q.AppID, KeyField: keyField}
+	var keys []string
+	for _, r := range rs {
+		key, err := attachmentKey(r, keyField)
+		if err != nil {
+			return nil, err
+		}
+		m.Attachments = append(m.Attachments, recordAttachments(key, r.Fields)...)
+		keys = append(keys, key)
+	}
+	if err := uniqueKeys(keyField, keys); err != nil {
+		return nil, err
+	}
+
+	// ダウンロードするファイルキーは Path の順と同じ
+	var fileKeys []string
+	for _, r := range rs {
+		for _, f := range recordFiles(r.Fields) {
+			fileKeys = append(fileKeys, f.FileKey)
+		}
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for i, a := range m.Attachments {
+		a, fileKey := a, fileKeys[i]
+		eg.Go(func() error {
+			return repo.downloadAttachment(ctx, fileKey, filepath.Join(dir, filepath.FromSlash(a.Path)))
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, AttachmentManifestName), data, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (repo *Repository) downloadAttachment(ctx context.Context, fileKey, path string) error {
+	select {
+	case repo.Token <- struct{}{}: // acquire token
+		defer func() {
+			<-repo.Token
+		}()
+	case <-ctx.Done(): // cancelled
+		return errors.New("canceled")
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = repo.DownloadFile(ctx, fileKey, f)
+	if err != nil {
+		return errors.Wrapf(err, "download %s failed", path)
+	}
+	return f.Close()
+}
+
+// ReadAttachmentManifest は ExportAttachments で書き出したマニフェストを読み込む
+func ReadAttachmentManifest(dir string) (*AttachmentManifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, AttachmentManifestName))
+	if err != nil {
+		return nil, err
+	}
+	var m AttachmentManifest
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ImportAttachments は ExportAttachments で書き出した添付ファイルをアップロードし、アプリのレコードに添付する
+// レコードはマニフェストの KeyField の値で探す。マニフェストにあるファイルフィールドの値は置き換えられる
+// テーブル内のファイルは同じ行番号の行に添付する
+func (repo *Repository) ImportAttachments(ctx context.Context, appID int, dir string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	m, err := ReadAttachmentManifest(dir)
+	if err != nil {
+		return errors.Wrap(err, "read manifest failed")
+	}
+	if len(m.Attachments) == 0 {
+		return nil
+	}
+
+	targets, err := repo.readAttachmentTargets(ctx, appID, m)
+	if err != nil {
+		return err
+	}
+
+	files := make([]*File, len(m.Attachments))
+	eg, ectx := errgroup.WithContext(ctx)
+	for i, a := range m.Attachments {
+		i, a := i, a
+		eg.Go(func() error {
+			f, err := repo.uploadAttachment(ectx, dir, a)
+			files[i] = f
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	rs, err := attachmentUpdates(m.Attachments, files, targets)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateRecords(ctx, appID, "", rs...)
+}
+
+// readAttachmentTargets はインポート先のレコードをキーごとに返す
+func (repo *Repository) readAttachmentTargets(ctx context.Context, appID int, m *AttachmentManifest) (map[string]*Record, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, a := range m.Attachments {
+		if !seen[a.Key] {
+			seen[a.Key] = true
+			keys = append(keys, fmt.Sprintf(`"%s"`, quoteEscaper.Replace(a.Key)))
+		}
+	}
+
+	q := &Query{AppID: appID, Condition: fmt.Sprintf("%s in (%s)", m.KeyField, strings.Join(keys, ","))}
+	rs, err := repo.ReadRecords(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "read target records failed")
+	}
+
+	targets := make(map[string]*Record, len(rs))
+	for _, r := range rs {
+		key, err := attachmentKey(r, m.KeyField)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := targets[key]; ok {
+			return nil, errors.Errorf("%s %s is duplicated in app %d", m.KeyField, key, appID)
+		}
+		targets[key] = r
+	}
+	return targets, nil
+}
+
+func (repo *Repository) uploadAttachment(ctx context.Context, dir string, a *Attachment) (*File, error) {
+	select {
+	case repo.Token <- struct{}{}: // acquire token
+		defer func() {
+			<-repo.Token
+		}()
+	case <-ctx.Done(): // cancelled
+		return nil, errors.New("canceled")
+	}
+
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(a.Path)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	key, err := repo.UploadFile(ctx, a.Name, a.ContentType, f)
+	if err != nil {
+		return nil, err
+	}
+	return &File{ContentType: a.ContentType, FileKey: key, Name: a.Name, Size: a.Size}, nil
+}
+
+// attachmentUpdates はアップロードしたファイルで更新するレコードを返す
+// テーブルは他の行を残すため、インポート先の行をコピーしてファイルフィールドを置き換える
+func attachmentUpdates(as []*Attachment, files []*File, targets map[string]*Record) ([]*Record, error) {
+	updates := make(map[string]*Record)
+	var rs []*Record
+
+	for i, a := range as {
+		target, ok := targets[a.Key]
+		if !ok {
+			return nil, errors.Errorf("record %s is not found", a.Key)
+		}
+
+		r, ok := updates[a.Key]
+		if !ok {
+			r = &Record{ID: target.ID, Fields: Fields{}}
+			updates[a.Key] = r
+			rs = append(rs, r)
+		}
+
+		if a.Table == "" {
+			ff, _ := r.Fields[a.Field].(FileField)
+			r.Fields[a.Field] = append(ff, files[i])
+			continue
+		}
+
+		table, ok := r.Fields[a.Table].(TableField)
+		if !ok {
+			orig, ok := target.Fields[a.Table].(TableField)
+			if !ok {
+				return nil, errors.Errorf("record %s: field %s is not a table", a.Key, a.Table)
+			}
+			table = make(TableField, len(orig))
+			for j, row := range orig {
+				table[j] = &Record{ID: row.ID, Fields: copyFields(row.Fields)}
+			}
+			// マニフェストにあるファイルフィールドは置き換える
+			for _, b := range as {
+				if b.Key == a.Key && b.Table == a.Table && b.Row < len(table) {
+					table[b.Row].Fields[b.Field] = FileField{}
+				}
+			}
+			r.Fields[a.Table] = table
+		}
+		if a.Row >= len(table) {
+			return nil, errors.Errorf("record %s: table %s has no row %d", a.Key, a.Table, a.Row)
+		}
+		row := table[a.Row]
+		row.Fields[a.Field] = append(row.Fields[a.Field].(FileField), files[i])
+	}
+
+	return rs, nil
+}
+
+// recordAttachments はレコードの添付ファイルを返す。順番は recordFiles と同じ
+func recordAttachments(key string, fs Fields) []*Attachment {
+	var as []*Attachment
+	add := func(table string, row int, code string, ff FileField) {
+		for i, f := range ff {
+			a := &Attachment{
+				Key:         key,
+				Table:       table,
+				Row:         row,
+				Field:       code,
+				Index:       i,
+				Name:        f.Name,
+				ContentType: f.ContentType,
+				Size:        f.Size,
+			}
+			elems := []string{safePathElement(key)}
+			if table != "" {
+				elems = append(elems, safePathElement(table), fmt.Sprint(row))
+			}
+			elems = append(elems, safePathElement(code), fmt.Sprintf("%d_%s", i, safePathElement(f.Name)))
+			a.Path = strings.Join(elems, "/")
+			as = append(as, a)
+		}
+	}
+	walkFileFields(fs, add)
+	return as
+}
+
+// recordFiles はレコードの添付ファイルを返す
+func recordFiles(fs Fields) []*File {
+	var files []*File
+	walkFileFields(fs, func(table string, row int, code string, ff FileField) {
+		files = append(files, ff...)
+	})
+	return files
+}
+
+// walkFileFields はテーブル内も含めてファイルフィールドをフィールドコードの順に f に渡す
+func walkFileFields(fs Fields, f func(table string, row int, code string, ff FileField)) {
+	codes := make([]string, 0, len(fs))
+	for code := range fs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		switch field := fs[code].(type) {
+		case FileField:
+			f("", 0, code, field)
+		case TableField:
+			for i, row := range field {
+				walkFileFields(row.Fields, func(_ string, _ int, c string, ff FileField) {
+					f(code, i, c, ff)
+				})
+			}
+		}
+	}
+}
+
+func attachmentKey(r *Record, keyField string) (string, error) {
+	if keyField == fieldCodeID && r.ID != "" {
+		return r.ID, nil
+	}
+	key, err := r.Fields.String(keyField)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return "", errors.Errorf("record %s: %s is empty", r.ID, keyField)
+	}
+	return key, nil
+}
+
+func uniqueKeys(keyField string, keys []string) error {
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if seen[key] {
+			return errors.Errorf("%s %s is duplicated", keyField, key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// safePathElement はファイル名に使えるように区切り文字を置き換える
+func safePathElement(s string) string {
+	s = strings.NewReplacer("/", "_", `\`, "_", "\x00", "_").Replace(s)
+	if s == "" || s == "." || s == ".." {
+		return "_"
+	}
+	return s
+}
@@ -0,0 +1,133 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testAttachmentRecords = `{"records": [{
+	"$id": {"type": "__ID__", "value": "9"},
+	"code": {"type": "SINGLE_LINE_TEXT", "value": "A-1"},
+	"files": {"type": "FILE", "value": [
+		{"contentType": "text/plain", "fileKey": "k1", "name": "a.txt", "size": "6"},
+		{"contentType": "text/plain", "fileKey": "k2", "name": "a.txt", "size": "6"}
+	]},
+	"table": {"type": "SUBTABLE", "value": [
+		{"id": "100", "value": {
+			"memo": {"type": "SINGLE_LINE_TEXT", "value": "x"},
+			"rowFiles": {"type": "FILE", "value": []}
+		}},
+		{"id": "101", "value": {
+			"memo": {"type": "SINGLE_LINE_TEXT", "value": "y"},
+			"rowFiles": {"type": "FILE", "value": [
+				{"contentType": "image/png", "fileKey": "k3", "name": "../b.png", "size": "6"}
+			]}
+		}}
+	]}
+}]}`
+
+func TestExportImportAttachments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kintone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	repo, _ := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		switch {
+		case req.Path == APIEndpointFile:
+			return []byte("data:" + req.FileKey), nil
+		case req.Query.limit == 0:
+			return []byte(`{"totalCount": "1"}`), nil
+		}
+		return []byte(testAttachmentRecords), nil
+	})
+
+	m, err := repo.ExportAttachments(context.Background(), &Query{AppID: 1}, dir, "code")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(m.Attachments) != 3 {
+		t.Errorf("unexpected attachments: %d", len(m.Attachments))
+		return
+	}
+
+	paths := map[string]string{
+		"A-1/files/0_a.txt":               "data:k1",
+		"A-1/files/1_a.txt":               "data:k2",
+		"A-1/table/1/rowFiles/0_.._b.png": "data:k3",
+	}
+	for _, a := range m.Attachments {
+		expected, ok := paths[a.Path]
+		if !ok {
+			t.Errorf("unexpected path: %s", a.Path)
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(a.Path)))
+		if err != nil || string(data) != expected {
+			t.Errorf("%s: expected: %s, actual: %s (%v)", a.Path, expected, data, err)
+		}
+	}
+	if a := m.Attachments[2]; a.Table != "table" || a.Row != 1 || a.Field != "rowFiles" || a.Name != "../b.png" {
+		t.Errorf("unexpected attachment: %#v", a)
+	}
+
+	// 別のアプリに同じ code のレコードがある
+	var update []byte
+	target, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		switch {
+		case req.Path == APIEndpointFile:
+			return []byte(`{"fileKey": "new-` + string(req.Body) + `"}`), nil
+		case req.Method == "PUT":
+			update = req.Body
+			return []byte(`{"records": [{"id": "20", "revision": "2"}]}`), nil
+		case req.Query.limit == 0:
+			return []byte(`{"totalCount": "1"}`), nil
+		}
+		return []byte(strings.Replace(testAttachmentRecords, `"value": "9"`, `"value": "20"`, 1)), nil
+	})
+
+	if err := target.ImportAttachments(context.Background(), 2, dir); err != nil {
+		t.Error(err)
+		return
+	}
+
+	var condition string
+	for _, req := range c.requests {
+		if req.Query != nil && req.Query.Condition != "" {
+			condition = req.Query.Condition
+		}
+	}
+	if condition != `code in ("A-1")` {
+		t.Errorf("unexpected condition: %s", condition)
+	}
+
+	var body struct {
+		Records []struct {
+			ID     string                     `json:"id"`
+			Record map[string]json.RawMessage `json:"record"`
+		} `json:"records"`
+	}
+	if err := json.Unmarshal(update, &body); err != nil || len(body.Records) != 1 {
+		t.Errorf("unexpected update: %s", update)
+		return
+	}
+	r := body.Records[0]
+	if r.ID != "20" {
+		t.Errorf("unexpected id: %s", r.ID)
+	}
+	files := string(r.Record["files"])
+	if !strings.Contains(files, "new-data:k1") || !strings.Contains(files, "new-data:k2") {
+		t.Errorf("unexpected files: %s", files)
+	}
+	table := string(r.Record["table"])
+	if !strings.Contains(table, `"100"`) || !strings.Contains(table, "new-data:k3") || strings.Contains(table, `"k3"`) {
+		t.Errorf("unexpected table: %s", table)
+	}
+}
@@ -2,10 +2,12 @@ package kintone
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -14,16 +16,51 @@ import (
 
 // APIEndpoint constants
 const (
-	APIEndpointBase          = "https://%s.cybozu.com"
-	APIEndpointRecord        = "/k/v1/record.json"
-	APIEndpointRecords       = "/k/v1/records.json"
-	APIEndpointRecordsCursor = "k/v1/records/cursor.json"
-	APIEndpointApp           = "/k/v1/app.json"
-	APIEndpointFormField     = "/k/v1/app/form/fields.json"
-	APIEndpointFormLayout    = "/k/v1/app/form/layout.json"
-	APIEndpointFile          = "/k/v1/file.json"
-	APIEndpointSpace         = "/k/v1/space.json"
-	APIEndpointCreateSpace   = "/k/v1/template/space.json"
+	APIEndpointBase                          = "https://%s.cybozu.com"
+	APIEndpointRecord                        = "/k/v1/record.json"
+	APIEndpointRecords                       = "/k/v1/records.json"
+	APIEndpointRecordStatus                  = "/k/v1/record/status.json"
+	APIEndpointRecordsStatus                 = "/k/v1/records/status.json"
+	APIEndpointRecordAssignees               = "/k/v1/record/assignees.json"
+	APIEndpointRecordComment                 = "/k/v1/record/comment.json"
+	APIEndpointRecordComments                = "/k/v1/record/comments.json"
+	APIEndpointRecordsCursor                 = "k/v1/records/cursor.json"
+	APIEndpointApp                           = "/k/v1/app.json"
+	APIEndpointApps                          = "/k/v1/apps.json"
+	APIEndpointMoveApp                       = "/k/v1/app/move.json"
+	APIEndpointPreviewApp                    = "/k/v1/preview/app.json"
+	APIEndpointFormField                     = "/k/v1/app/form/fields.json"
+	APIEndpointFormLayout                    = "/k/v1/app/form/layout.json"
+	APIEndpointPreviewFormField              = "/k/v1/preview/app/form/fields.json"
+	APIEndpointPreviewFormLayout             = "/k/v1/preview/app/form/layout.json"
+	APIEndpointPreviewDeploy                 = "/k/v1/preview/app/deploy.json"
+	APIEndpointAppSettings                   = "/k/v1/app/settings.json"
+	APIEndpointViews                         = "/k/v1/app/views.json"
+	APIEndpointProcess                       = "/k/v1/app/status.json"
+	APIEndpointAppACL                        = "/k/v1/app/acl.json"
+	APIEndpointRecordACL                     = "/k/v1/record/acl.json"
+	APIEndpointFieldACL                      = "/k/v1/field/acl.json"
+	APIEndpointRecordsACLEvaluate            = "/k/v1/records/acl/evaluate.json"
+	APIEndpointGeneralNotifications          = "/k/v1/app/notifications/general.json"
+	APIEndpointPerRecordNotifications        = "/k/v1/app/notifications/perRecord.json"
+	APIEndpointReminderNotifications         = "/k/v1/app/notifications/reminder.json"
+	APIEndpointPreviewAppSettings            = "/k/v1/preview/app/settings.json"
+	APIEndpointPreviewViews                  = "/k/v1/preview/app/views.json"
+	APIEndpointPreviewProcess                = "/k/v1/preview/app/status.json"
+	APIEndpointPreviewAppACL                 = "/k/v1/preview/app/acl.json"
+	APIEndpointPreviewRecordACL              = "/k/v1/preview/record/acl.json"
+	APIEndpointPreviewFieldACL               = "/k/v1/preview/field/acl.json"
+	APIEndpointPreviewGeneralNotifications   = "/k/v1/preview/app/notifications/general.json"
+	APIEndpointPreviewPerRecordNotifications = "/k/v1/preview/app/notifications/perRecord.json"
+	APIEndpointPreviewReminderNotifications  = "/k/v1/preview/app/notifications/reminder.json"
+	APIEndpointFile                          = "/k/v1/file.json"
+	APIEndpointSpace                         = "/k/v1/space.json"
+	APIEndpointCreateSpace                   = "/k/v1/template/space.json"
+	APIEndpointSpaceBody                     = "/k/v1/space/body.json"
+	APIEndpointSpaceMembers                  = "/k/v1/space/members.json"
+	APIEndpointSpaceThread                   = "/k/v1/space/thread.json"
+	APIEndpointSpaceThreadComment            = "/k/v1/space/thread/comment.json"
+	APIEndpointGuestSpaceGuests              = "/k/guest/%d/v1/space/guests.json"
 )
 
 // Client ...
@@ -33,6 +70,8 @@ type Client interface {
 	post(path string, body []byte) ([]byte, error)
 	put(path string, body []byte) ([]byte, error)
 	delete(path string, body []byte) ([]byte, error)
+	upload(ctx context.Context, name, contentType string, r io.Reader) ([]byte, error)
+	download(ctx context.Context, fileKey string, w io.Writer) error
 	SetBasicAuth(username, password string)
 }
 
@@ -58,6 +97,7 @@ var (
 	ErrTimeout         = errors.New("Timeout")
 	ErrInvalidResponse = errors.New("Invalid Response")
 	ErrTooMany         = errors.New("Too many records")
+	ErrNotFound        = errors.New("Record not found")
 )
 
 // ClientError ...
@@ -211,6 +251,18 @@ func (c *client) delete(path string, body []byte) ([]byte, error) {
 }
 
 func (c *client) do(req *http.Request) ([]byte, error) {
+	res, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	return ioutil.ReadAll(res.Body)
+}
+
+// send は認証ヘッダーを付けてリクエストを送る
+// ステータスが 200 以外の場合はレスポンスを閉じてエラーを返す
+func (c *client) send(req *http.Request) (*http.Response, error) {
 	req.Header.Set("X-Cybozu-Authorization", c.apiToken)
 
 	if c.basicAuthName != "" && c.basicAuthPassword != "" {
@@ -222,22 +274,24 @@ func (c *client) do(req *http.Request) ([]byte, error) {
 		return nil, err
 	}
 
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-
 	if res.StatusCode != 200 {
-		var e resError
-		err = json.Unmarshal(body, &e)
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(res.Body)
 		if err != nil {
 			return nil, err
 		}
+
+		e := resError{HTTPStatusCode: res.StatusCode}
+		err = json.Unmarshal(body, &e)
+		if err != nil {
+			// プロキシのエラーページなど JSON 以外のレスポンス
+			return nil, &resError{HTTPStatusCode: res.StatusCode, Message: res.Status}
+		}
 		return nil, &e
 	}
 
-	return body, nil
+	return res, nil
 }
 
 func newURL(endpointBase *url.URL, path string, q *Query) (string, error) {
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yoheimiyamoto/kintone"
+)
+
+type config struct {
+	Package string
+	Type    string
+	AppID   int
+}
+
+type goStruct struct {
+	Name   string
+	Fields []*goField
+}
+
+type goField struct {
+	Name     string
+	Type     string
+	Code     string
+	Label    string
+	Options  []string // タグのオプション
+	Constant string   // フィールドコードの定数名
+}
+
+type goEnum struct {
+	Name   string
+	Label  string
+	Values []*goEnumValue
+}
+
+type goEnumValue struct {
+	Name  string
+	Value string
+}
+
+type generator struct {
+	config  *config
+	structs []*goStruct
+	enums   []*goEnum
+	types   map[string]bool // 生成済みの型名、定数名
+	kintone bool            // kintone パッケージを使用するか
+	time    bool            // time パッケージを使用するか
+}
+
+// generate はフォームの設定から Go のソースコードを生成する
+// 同じ設定からは常に同じ出力になるよう、フィールドはレイアウト順、レイアウトに無いものはコード順に並べる
+func generate(c *config, form kintone.FormFields, layout kintone.FormLayouts) ([]byte, error) {
+	g := &generator{config: c, types: make(map[string]bool)}
+
+	order, tables := layoutOrder(layout)
+	g.buildStruct(c.Type, form, order, tables, false)
+
+	var buf bytes.Buffer
+	g.write(&buf)
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code failed: %s", err)
+	}
+	return src, nil
+}
+
+// layoutOrder はレイアウトに配置されたフィールドコードを配置順に返す
+// サブテーブル内のフィールドはテーブルのコードごとに返す
+func layoutOrder(layouts kintone.FormLayouts) ([]string, map[string][]string) {
+	var order []string
+	tables := make(map[string][]string)
+
+	var walk func(layouts []*kintone.FormLayout)
+	walk = func(layouts []*kintone.FormLayout) {
+		for _, l := range layouts {
+			switch l.Type {
+			case "SUBTABLE":
+				order = append(order, l.Code)
+				for _, f := range l.Fields {
+					tables[l.Code] = append(tables[l.Code], f.Code)
+				}
+				continue
+			case "GROUP":
+				order = append(order, l.Code)
+			}
+			for _, f := range l.Fields {
+				if f.Code != "" {
+					order = append(order, f.Code)
+				}
+			}
+			walk(l.Layouts)
+		}
+	}
+	walk(layouts)
+
+	return order, tables
+}
+
+// sortedCodes は order の順に、order に無いコードはその後ろにコード順で並べる
+func sortedCodes(form kintone.FormFields, order []string) []string {
+	var codes []string
+	seen := make(map[string]bool)
+
+	for _, code := range order {
+		if _, ok := form[code]; ok && !seen[code] {
+			codes = append(codes, code)
+			seen[code] = true
+		}
+	}
+
+	var rest []string
+	for code := range form {
+		if !seen[code] {
+			rest = append(rest, code)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(codes, rest...)
+}
+
+func (g *generator) typeName(name string) string {
+	name = uniqueName(g.types, name)
+	g.types[name] = true
+	return name
+}
+
+func (g *generator) buildStruct(name string, form kintone.FormFields, order []string, tables map[string][]string, row bool) string {
+	s := &goStruct{Name: g.typeName(name)}
+	g.structs = append(g.structs, s)
+
+	names := map[string]bool{"ID": true}
+	s.Fields = append(s.Fields, &goField{Name: "ID", Type: "string", Code: "$id"})
+	if !row {
+		names["Revision"] = true
+		s.Fields = append(s.Fields, &goField{Name: "Revision", Type: "string", Code: "$revision"})
+	}
+
+	for _, code := range sortedCodes(form, order) {
+		ff := form[code]
+		if ff == nil {
+			continue
+		}
+
+		fieldName := uniqueName(names, exportedName(code))
+
+		f := &goField{
+			Name:  fieldName,
+			Code:  code,
+			Label: ff.Label,
+		}
+
+		if !g.fieldType(s.Name, f, ff, tables) {
+			continue
+		}
+
+		names[fieldName] = true
+		f.Constant = g.typeName(s.Name + "Field" + fieldName)
+		s.Fields = append(s.Fields, f)
+	}
+
+	return s.Name
+}
+
+// fieldType はフィールドタイプに対応する Go の型を設定する
+// レコードの値を持たないフィールド（ラベル、関連レコード一覧など）は false を返す
+func (g *generator) fieldType(structName string, f *goField, ff *kintone.FormField, tables map[string][]string) bool {
+	useKintone := func(t string) string {
+		g.kintone = true
+		return "kintone." + t
+	}
+	useTime := func() string {
+		g.time = true
+		return "*time.Time"
+	}
+
+	switch ff.Type {
+	case kintone.FieldTypeSingleLineText, kintone.FieldTypeMultiLineText, kintone.FieldTypeRichText, kintone.FieldTypeLink:
+		f.Type = "string"
+	case kintone.FieldTypeNumber:
+		f.Type = useKintone("Decimal")
+	case kintone.FieldTypeCalc:
+		f.Type = useKintone("CalcField")
+		f.Options = []string{"readonly"}
+	case kintone.FieldTypeRadioButton, kintone.FieldTypeSingleSelect:
+		f.Type = g.buildEnum(structName+f.Name, ff)
+	case kintone.FieldTypeCheckBox, kintone.FieldTypeMultiSelect:
+		f.Type = "[]" + g.buildEnum(structName+f.Name, ff)
+	case kintone.FieldTypeDate:
+		f.Type = "*" + useKintone("Date")
+	case kintone.FieldTypeTime:
+		f.Type = useTime()
+		f.Options = []string{"time"}
+	case kintone.FieldTypeDateTime:
+		f.Type = useTime()
+	case kintone.FieldTypeCreatedDateTime, kintone.FieldTypeUpdatedDateTime:
+		f.Type = useTime()
+		f.Options = []string{"readonly"}
+	case kintone.FieldTypeUsers:
+		f.Type = "[]*" + useKintone("UserField")
+	case kintone.FieldTypeOrganization:
+		f.Type = "[]*" + useKintone("OrganizationField")
+	case kintone.FieldTypeGroup:
+		f.Type = "[]*" + useKintone("GroupField")
+	case kintone.FieldTypeAssignee:
+		f.Type = "[]*" + useKintone("UserField")
+		f.Options = []string{"readonly"}
+	case kintone.FieldTypeCreator, kintone.FieldTypeModifier:
+		f.Type = "*" + useKintone("UserField")
+		f.Options = []string{"readonly"}
+	case kintone.FieldTypeFile:
+		f.Type = useKintone("FileField")
+	case kintone.FieldTypeRecordNumber, kintone.FieldTypeStatus:
+		f.Type = "string"
+		f.Options = []string{"readonly"}
+	case kintone.FieldTypeCategory:
+		f.Type = "[]string"
+		f.Options = []string{"readonly"}
+	case kintone.FieldTypeSubtable:
+		f.Type = "[]*" + g.buildStruct(structName+f.Name, ff.Fields, tables[f.Code], nil, true)
+	default:
+		return false
+	}
+	return true
+}
+
+func (g *generator) buildEnum(name string, ff *kintone.FormField) string {
+	e := &goEnum{Name: g.typeName(name), Label: ff.Label}
+	g.enums = append(g.enums, e)
+
+	for i, o := range ff.Options {
+		if o == "" {
+			continue
+		}
+		n := identifier(o)
+		if n == "" {
+			n = "Value" + strconv.Itoa(i)
+		}
+		// 値の定数は構造体、他の列挙型の値と同じパッケージのスコープに出力される
+		n = g.typeName(e.Name + n)
+		e.Values = append(e.Values, &goEnumValue{Name: n, Value: o})
+	}
+
+	return e.Name
+}
+
+//+write
+
+func (g *generator) write(buf *bytes.Buffer) {
+	fmt.Fprintln(buf, "// Code generated by kintone-gen. DO NOT EDIT.")
+	if g.config.AppID != 0 {
+		fmt.Fprintf(buf, "// app: %d\n", g.config.AppID)
+	}
+	fmt.Fprintln(buf)
+	fmt.Fprintf(buf, "package %s\n\n", g.config.Package)
+
+	if g.kintone || g.time {
+		fmt.Fprintln(buf, "import (")
+		if g.time {
+			fmt.Fprintln(buf, `"time"`)
+		}
+		if g.kintone {
+			fmt.Fprintln(buf)
+			fmt.Fprintln(buf, `"github.com/yoheimiyamoto/kintone"`)
+		}
+		fmt.Fprintln(buf, ")")
+		fmt.Fprintln(buf)
+	}
+
+	for _, s := range g.structs {
+		g.writeStruct(buf, s)
+	}
+
+	for _, e := range g.enums {
+		g.writeEnum(buf, e)
+	}
+}
+
+func (g *generator) writeStruct(buf *bytes.Buffer, s *goStruct) {
+	fmt.Fprintf(buf, "// %s のフィールドコード\n", s.Name)
+	fmt.Fprintln(buf, "const (")
+	for _, f := range s.Fields {
+		if f.Constant == "" {
+			continue
+		}
+		fmt.Fprintf(buf, "%s = %s\n", f.Constant, strconv.Quote(f.Code))
+	}
+	fmt.Fprintln(buf, ")")
+	fmt.Fprintln(buf)
+
+	fmt.Fprintf(buf, "// %s ...\n", s.Name)
+	fmt.Fprintf(buf, "type %s struct {\n", s.Name)
+	for _, f := range s.Fields {
+		tag := strings.Join(append([]string{f.Code}, f.Options...), ",")
+		fmt.Fprintf(buf, "%s %s `kintone:%s`", f.Name, f.Type, strconv.Quote(tag))
+		if f.Label != "" {
+			fmt.Fprintf(buf, " // %s", oneLine(f.Label))
+		}
+		fmt.Fprintln(buf)
+	}
+	fmt.Fprintln(buf, "}")
+	fmt.Fprintln(buf)
+}
+
+func (g *generator) writeEnum(buf *bytes.Buffer, e *goEnum) {
+	fmt.Fprintf(buf, "// %s は「%s」の選択肢\n", e.Name, oneLine(e.Label))
+	fmt.Fprintf(buf, "type %s string\n\n", e.Name)
+
+	if len(e.Values) == 0 {
+		return
+	}
+
+	fmt.Fprintf(buf, "// %s の選択肢\n", e.Name)
+	fmt.Fprintln(buf, "const (")
+	for _, v := range e.Values {
+		fmt.Fprintf(buf, "%s %s = %s\n", v.Name, e.Name, strconv.Quote(v.Value))
+	}
+	fmt.Fprintln(buf, ")")
+	fmt.Fprintln(buf)
+}
+
+//-write
+
+//+name
+
+// identifier は英数字以外を区切りとして単語の先頭を大文字にした識別子を返す
+func identifier(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// exportedName はエクスポートされる識別子を返す
+// 漢字や数字など大文字にできない文字で始まる場合は F を付ける
+func exportedName(s string) string {
+	name := identifier(s)
+	if name == "" {
+		return "Field"
+	}
+	r, _ := utf8.DecodeRuneInString(name)
+	if !unicode.IsUpper(r) {
+		name = "F" + name
+	}
+	return name
+}
+
+func uniqueName(names map[string]bool, name string) string {
+	if !names[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		n := name + strconv.Itoa(i)
+		if !names[n] {
+			return n
+		}
+	}
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+//-name
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/yoheimiyamoto/kintone"
+)
+
+var testForm = []byte(`
+	{
+		"customer_name": {"type": "SINGLE_LINE_TEXT", "code": "customer_name", "label": "顧客名"},
+		"ランク": {
+			"type": "DROP_DOWN",
+			"code": "ランク",
+			"label": "ランク",
+			"options": {
+				"gold": {"label": "gold", "index": "0"},
+				"silver plan": {"label": "silver plan", "index": "1"}
+			}
+		},
+		"tags": {
+			"type": "CHECK_BOX",
+			"code": "tags",
+			"label": "タグ",
+			"options": {
+				"a": {"label": "a", "index": "1"},
+				"b": {"label": "b", "index": "0"}
+			}
+		},
+		"price": {"type": "NUMBER", "code": "price", "label": "金額"},
+		"due": {"type": "DATE", "code": "due", "label": "期限"},
+		"owner": {"type": "USER_SELECT", "code": "owner", "label": "担当者"},
+		"レコード番号": {"type": "RECORD_NUMBER", "code": "レコード番号", "label": "レコード番号"},
+		"related": {"type": "REFERENCE_TABLE", "code": "related", "label": "関連"},
+		"orders": {
+			"type": "SUBTABLE",
+			"code": "orders",
+			"fields": {
+				"product": {"type": "SINGLE_LINE_TEXT", "code": "product", "label": "商品"},
+				"quantity": {"type": "NUMBER", "code": "quantity", "label": "数量"}
+			}
+		}
+	}
+`)
+
+var testLayout = []byte(`
+	[
+		{
+			"type": "ROW",
+			"fields": [
+				{"type": "NUMBER", "code": "price"},
+				{"type": "SINGLE_LINE_TEXT", "code": "customer_name"}
+			]
+		},
+		{
+			"type": "SUBTABLE",
+			"code": "orders",
+			"fields": [
+				{"type": "NUMBER", "code": "quantity"},
+				{"type": "SINGLE_LINE_TEXT", "code": "product"}
+			]
+		}
+	]
+`)
+
+func testGenerate(t *testing.T) string {
+	t.Helper()
+
+	var form kintone.FormFields
+	err := json.Unmarshal(testForm, &form)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var layout kintone.FormLayouts
+	err = json.Unmarshal(testLayout, &layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generate(&config{Package: "customer", Type: "Customer", AppID: 10}, form, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(src)
+}
+
+func TestGenerate(t *testing.T) {
+	actual := testGenerate(t)
+
+	for _, s := range []string{
+		"// Code generated by kintone-gen. DO NOT EDIT.",
+		"package customer",
+		`CustomerFieldCustomerName`,
+		"type Customer struct {",
+		"`kintone:\"customer_name\"` // 顧客名",
+		"kintone.Decimal",
+		"*kintone.Date",
+		"`kintone:\"due\"`",
+		"[]*kintone.UserField",
+		"`kintone:\"レコード番号,readonly\"`",
+		"[]*CustomerOrders",
+		"type CustomerOrders struct {",
+		"type CustomerFランク string",
+		`CustomerFランクSilverPlan`,
+		`= "silver plan"`,
+		"[]CustomerTags",
+	} {
+		if !strings.Contains(actual, s) {
+			t.Errorf("%q is not contained in:\n%s", s, actual)
+		}
+	}
+
+	if strings.Contains(actual, "related") {
+		t.Errorf("REFERENCE_TABLE should be skipped:\n%s", actual)
+	}
+
+	// レイアウト順
+	if strings.Index(actual, "Price ") > strings.Index(actual, "CustomerName ") {
+		t.Errorf("fields should be ordered by layout:\n%s", actual)
+	}
+	if strings.Index(actual, "Quantity ") > strings.Index(actual, "Product ") {
+		t.Errorf("subtable fields should be ordered by layout:\n%s", actual)
+	}
+
+	// 選択肢は index 順
+	if strings.Index(actual, "CustomerTagsB ") > strings.Index(actual, "CustomerTagsA ") {
+		t.Errorf("options should be ordered by index:\n%s", actual)
+	}
+}
+
+func TestGenerateStable(t *testing.T) {
+	expected := testGenerate(t)
+	for i := 0; i < 10; i++ {
+		actual := testGenerate(t)
+		if expected != actual {
+			t.Errorf("expected: %s, actual: %s", expected, actual)
+			return
+		}
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"customer_name", "CustomerName"},
+		{"文字列__1行", "F文字列1行"},
+		{"2nd", "F2nd"},
+		{"__", "Field"},
+	}
+
+	for _, test := range tests {
+		actual := exportedName(test.input)
+		if test.expected != actual {
+			t.Errorf("expected: %s, actual: %s", test.expected, actual)
+		}
+	}
+}
+
+func TestGenerateNameCollision(t *testing.T) {
+	// 列挙型の値 CustomerStatusDone とテーブルの構造体 CustomerStatusDone が同じ名前になる
+	var form kintone.FormFields
+	err := json.Unmarshal([]byte(`{
+		"status": {
+			"type": "RADIO_BUTTON",
+			"code": "status",
+			"label": "状態",
+			"options": {"done": {"label": "done", "index": "0"}}
+		},
+		"status_done": {
+			"type": "SUBTABLE",
+			"code": "status_done",
+			"fields": {"note": {"type": "SINGLE_LINE_TEXT", "code": "note", "label": "メモ"}}
+		}
+	}`), &form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// テーブルの構造体を先に生成する
+	var layout kintone.FormLayouts
+	err = json.Unmarshal([]byte(`[
+		{"type": "SUBTABLE", "code": "status_done", "fields": [{"type": "SINGLE_LINE_TEXT", "code": "note"}]},
+		{"type": "ROW", "fields": [{"type": "RADIO_BUTTON", "code": "status"}]}
+	]`), &layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generate(&config{Package: "customer", Type: "Customer"}, form, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "customer.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, d := range f.Decls {
+		gd, ok := d.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			var names []*ast.Ident
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				names = []*ast.Ident{spec.Name}
+			case *ast.ValueSpec:
+				names = spec.Names
+			}
+			for _, n := range names {
+				if seen[n.Name] {
+					t.Errorf("%s is declared twice:\n%s", n.Name, src)
+				}
+				seen[n.Name] = true
+			}
+		}
+	}
+	if !seen["CustomerStatusDone"] || !seen["CustomerStatusDone2"] {
+		t.Errorf("unexpected names:\n%s", src)
+	}
+}
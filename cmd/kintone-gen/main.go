@@ -0,0 +1,71 @@
+// kintone-gen はアプリのフォーム設定から Go の型を生成する
+//
+//	//go:generate kintone-gen -app 10 -type Customer -o customer_gen.go
+//
+// 接続先は環境変数 KINTONE_DOMAIN, KINTONE_ID, KINTONE_PASSWORD で指定する。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/yoheimiyamoto/kintone"
+)
+
+func main() {
+	var (
+		domain   = flag.String("domain", os.Getenv("KINTONE_DOMAIN"), "kintone のサブドメイン")
+		user     = flag.String("user", os.Getenv("KINTONE_ID"), "ログイン名")
+		password = flag.String("password", os.Getenv("KINTONE_PASSWORD"), "パスワード")
+		appID    = flag.Int("app", 0, "アプリ ID")
+		typeName = flag.String("type", "", "生成する構造体の名前")
+		pkg      = flag.String("package", os.Getenv("GOPACKAGE"), "生成するファイルのパッケージ名")
+		output   = flag.String("o", "", "出力先のファイル（省略時は標準出力）")
+	)
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("kintone-gen: ")
+
+	if *appID == 0 || *typeName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *pkg == "" {
+		*pkg = "main"
+	}
+
+	repo := kintone.NewRepository(*domain, *user, *password, nil)
+
+	form, err := repo.ReadFormFields(*appID)
+	if err != nil {
+		log.Fatalf("read form fields failed: %s", err)
+	}
+
+	layout, err := repo.ReadFormLayout(*appID)
+	if err != nil {
+		log.Fatalf("read form layout failed: %s", err)
+	}
+
+	src, err := generate(&config{
+		Package: *pkg,
+		Type:    *typeName,
+		AppID:   *appID,
+	}, form, layout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *output == "" {
+		fmt.Print(string(src))
+		return
+	}
+
+	err = ioutil.WriteFile(*output, src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
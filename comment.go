@@ -0,0 +1,212 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// commentsLimit は一度に取得できるコメントの最大数
+const commentsLimit = 10
+
+// コメントの並び順
+const (
+	CommentOrderAsc  = "asc"
+	CommentOrderDesc = "desc"
+)
+
+// Comment はレコードのコメント
+type Comment struct {
+	ID        string     `json:"id"`
+	Text      string     `json:"text"`
+	CreatedAt time.Time  `json:"createdAt"`
+	Creator   *UserField `json:"creator"`
+	Mentions  []*Entity  `json:"mentions"`
+}
+
+// CommentQuery はコメントの取得条件
+type CommentQuery struct {
+	Order  string // asc or desc。空の場合は desc
+	Offset int
+	Limit  int // 最大 10。0 の場合は 10
+}
+
+// CommentPage は ReadComments の結果
+type CommentPage struct {
+	Comments []*Comment `json:"comments"`
+	Older    bool       `json:"older"` // より古いコメントがあるかどうか
+	Newer    bool       `json:"newer"` // より新しいコメントがあるかどうか
+}
+
+// AddComment はレコードにコメントを書き込み、コメント ID を返す
+// mentions にはメンションするユーザー、組織、グループを指定する
+func (repo *Repository) AddComment(appID int, recordID string, text string, mentions ...*Entity) (string, error) {
+	type comment struct {
+		Text     string    `json:"text"`
+		Mentions []*Entity `json:"mentions,omitempty"`
+	}
+	body, err := json.Marshal(struct {
+		App     int      `json:"app"`
+		Record  string   `json:"record"`
+		Comment *comment `json:"comment"`
+	}{appID, recordID, &comment{text, mentions}})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := repo.Client.post(APIEndpointRecordComment, body)
+	if err != nil {
+		return "", err
+	}
+
+	raw := struct {
+		ID string `json:"id"`
+	}{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return "", err
+	}
+	return raw.ID, nil
+}
+
+// DeleteComment はレコードのコメントを削除する
+func (repo *Repository) DeleteComment(appID int, recordID string, commentID string) error {
+	body, err := json.Marshal(struct {
+		App     int    `json:"app"`
+		Record  string `json:"record"`
+		Comment string `json:"comment"`
+	}{appID, recordID, commentID})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Client.delete(APIEndpointRecordComment, body)
+	return err
+}
+
+// ReadComments はレコードのコメントを最大 10 件返す
+func (repo *Repository) ReadComments(appID int, recordID string, q *CommentQuery) (*CommentPage, error) {
+	if q == nil {
+		q = &CommentQuery{}
+	}
+	if q.Limit > commentsLimit {
+		return nil, errors.Errorf("limit must be %d or less", commentsLimit)
+	}
+
+	body, err := json.Marshal(struct {
+		App    int    `json:"app"`
+		Record string `json:"record"`
+		Order  string `json:"order,omitempty"`
+		Offset int    `json:"offset,omitempty"`
+		Limit  int    `json:"limit,omitempty"`
+	}{appID, recordID, q.Order, q.Offset, q.Limit})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := repo.Client.getWithBody(APIEndpointRecordComments, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var page CommentPage
+	err = json.Unmarshal(data, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// CommentIterator はレコードの全てのコメントを順に返す
+//
+//	it := repo.Comments(ctx, appID, recordID, kintone.CommentOrderAsc)
+//	for it.Next() {
+//		c := it.Comment()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type CommentIterator struct {
+	ctx      context.Context
+	repo     *Repository
+	appID    int
+	recordID string
+	order    string
+
+	offset   int
+	comments []*Comment
+	current  *Comment
+	done     bool
+	err      error
+}
+
+// Comments はレコードの全てのコメントを 10 件ずつ取得する CommentIterator を返す
+func (repo *Repository) Comments(ctx context.Context, appID int, recordID string, order string) *CommentIterator {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &CommentIterator{ctx: ctx, repo: repo, appID: appID, recordID: recordID, order: order}
+}
+
+// Next は次のコメントに進む。コメントが無い場合やエラーの場合は false を返す
+func (it *CommentIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.comments) == 0 {
+		if it.done {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.comments) == 0 {
+			return false
+		}
+	}
+
+	it.current, it.comments = it.comments[0], it.comments[1:]
+	return true
+}
+
+func (it *CommentIterator) fetch() error {
+	select {
+	case <-it.ctx.Done():
+		return errors.New("canceled")
+	default:
+	}
+
+	page, err := it.repo.ReadComments(it.appID, it.recordID, &CommentQuery{
+		Order:  it.order,
+		Offset: it.offset,
+		Limit:  commentsLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	it.comments = page.Comments
+	it.offset += len(page.Comments)
+
+	// 並び順の先にコメントが無ければ終わり
+	more := page.Older
+	if it.order == CommentOrderAsc {
+		more = page.Newer
+	}
+	it.done = !more || len(page.Comments) == 0
+	return nil
+}
+
+// Comment は現在のコメントを返す
+func (it *CommentIterator) Comment() *Comment {
+	return it.current
+}
+
+// Err は取得に失敗した場合のエラーを返す
+func (it *CommentIterator) Err() error {
+	return it.err
+}
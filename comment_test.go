@@ -0,0 +1,113 @@
+package kintone
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestAddComment(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"id": "4"}`), nil
+	})
+
+	id, err := repo.AddComment(3, "5", "確認お願いします", &Entity{Code: "sato", Type: EntityTypeUser}, &Entity{Code: "sales", Type: EntityTypeOrganization})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if id != "4" {
+		t.Errorf("expected: 4, actual: %s", id)
+	}
+	expected := []byte(`{"app": 3, "record": "5", "comment": {"text": "確認お願いします", "mentions": [
+		{"code": "sato", "type": "USER"},
+		{"code": "sales", "type": "ORGANIZATION"}
+	]}}`)
+	if req := c.requests[0]; req.Method != "POST" || req.Path != APIEndpointRecordComment || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+
+	if err := repo.DeleteComment(3, "5", "4"); err != nil {
+		t.Error(err)
+		return
+	}
+	expected = []byte(`{"app": 3, "record": "5", "comment": "4"}`)
+	if req := c.requests[1]; req.Method != "DELETE" || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+}
+
+func TestReadComments(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{
+			"comments": [{
+				"id": "2",
+				"text": "sato \n確認しました",
+				"createdAt": "2020-01-02T03:04:05Z",
+				"creator": {"code": "suzuki", "name": "鈴木"},
+				"mentions": [{"code": "sato", "type": "USER"}]
+			}],
+			"older": true,
+			"newer": false
+		}`), nil
+	})
+
+	page, err := repo.ReadComments(3, "5", &CommentQuery{Order: CommentOrderDesc, Limit: 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(page.Comments) != 1 || !page.Older || page.Newer {
+		t.Errorf("unexpected page: %#v", page)
+		return
+	}
+	if cm := page.Comments[0]; cm.ID != "2" || cm.Creator.Code != "suzuki" || cm.Mentions[0].Code != "sato" || cm.CreatedAt.Day() != 2 {
+		t.Errorf("unexpected comment: %#v", cm)
+	}
+	expected := []byte(`{"app": 3, "record": "5", "order": "desc", "limit": 1}`)
+	if req := c.requests[0]; req.Path != APIEndpointRecordComments || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+
+	if _, err := repo.ReadComments(3, "5", &CommentQuery{Limit: 11}); err == nil {
+		t.Error("expected error for limit")
+	}
+}
+
+func TestComments(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		var body struct {
+			Offset int `json:"offset"`
+		}
+		json.Unmarshal(req.Body, &body)
+
+		// 全部で 25 件のコメント
+		n := 10
+		if 25-body.Offset < n {
+			n = 25 - body.Offset
+		}
+		comments := make([]string, n)
+		for i := range comments {
+			comments[i] = fmt.Sprintf(`{"id": "%d"}`, body.Offset+i+1)
+		}
+		newer := body.Offset+n < 25
+		return []byte(fmt.Sprintf(`{"comments": [%s], "older": %v, "newer": %v}`, strings.Join(comments, ","), body.Offset > 0, newer)), nil
+	})
+
+	it := repo.Comments(nil, 3, "5", CommentOrderAsc)
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Comment().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Error(err)
+		return
+	}
+	if len(ids) != 25 || ids[0] != "1" || ids[24] != "25" {
+		t.Errorf("unexpected comments: %v", ids)
+	}
+	if len(c.requests) != 3 {
+		t.Errorf("unexpected requests: %d", len(c.requests))
+	}
+}
@@ -0,0 +1,106 @@
+package kintone
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Date はタイムゾーンを持たない日付
+// 日付フィールドの値は壁時計の日付のため、タイムゾーンの変換で日付がずれないよう time.Time とは区別する
+// ゼロ値は値が空であることを表す
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate は日付を返す。範囲外の値は time.Date と同様に正規化する
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf は t のロケーションでの日付を返す
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{y, m, d}
+}
+
+// Today は loc での今日の日付を返す
+func Today(loc *time.Location) Date {
+	if loc == nil {
+		loc = time.Local
+	}
+	return DateOf(time.Now().In(loc))
+}
+
+// ParseDate は "2006-01-02" 形式の文字列を Date に変換する
+// 空文字の場合は空の Date を返す
+func ParseDate(s string) (Date, error) {
+	if s == "" {
+		return Date{}, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date: %q", s)
+	}
+	return DateOf(t), nil
+}
+
+// IsZero は値が空かどうかを返す
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// In は loc での d の 0 時を返す
+func (d Date) In(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.Local
+	}
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDate は d に年月日を加えた日付を返す
+func (d Date) AddDate(years, months, days int) Date {
+	return DateOf(d.In(time.UTC).AddDate(years, months, days))
+}
+
+// Before は d が x より前の日付かどうかを返す
+func (d Date) Before(x Date) bool {
+	return d.In(time.UTC).Before(x.In(time.UTC))
+}
+
+// After は d が x より後の日付かどうかを返す
+func (d Date) After(x Date) bool {
+	return d.In(time.UTC).After(x.In(time.UTC))
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.In(time.UTC).Format("2006-01-02")
+}
+
+// MarshalJSON ...
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON ...
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	v, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
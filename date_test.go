@@ -0,0 +1,127 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Date
+		err      bool
+	}{
+		{"2020-01-31", Date{2020, time.January, 31}, false},
+		{"", Date{}, false},
+		{"2020/01/31", Date{}, true},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseDate(test.input)
+		if test.err != (err != nil) {
+			t.Errorf("%s: unexpected error: %v", test.input, err)
+			continue
+		}
+		if test.expected != actual {
+			t.Errorf("expected: %v, actual: %v", test.expected, actual)
+		}
+	}
+}
+
+func TestDateOf(t *testing.T) {
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+
+	// 日本時間の 0 時台は UTC では前日になる
+	v := time.Date(2020, 1, 2, 0, 30, 0, 0, tokyo)
+	if actual := DateOf(v); actual != (Date{2020, time.January, 2}) {
+		t.Errorf("unexpected date: %v", actual)
+	}
+
+	f := NewDateTimeFieldIn(2020, 1, 2, 0, 30, tokyo)
+	if actual := f.String(); actual != "2020-01-02T00:30:00+09:00" {
+		t.Errorf("unexpected datetime: %s", actual)
+	}
+	if actual := f.In(time.UTC).Date(nil); actual != (Date{2020, time.January, 1}) {
+		t.Errorf("unexpected date: %v", actual)
+	}
+	if actual := f.In(time.UTC).Date(tokyo); actual != (Date{2020, time.January, 2}) {
+		t.Errorf("unexpected date: %v", actual)
+	}
+
+	d := NewDate(2020, time.January, 32)
+	if d.String() != "2020-02-01" || !d.After(NewDate(2020, time.January, 31)) {
+		t.Errorf("unexpected date: %v", d)
+	}
+}
+
+func TestTimeField(t *testing.T) {
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+
+	f := TimeFieldOf(time.Date(2020, 1, 2, 9, 5, 0, 0, tokyo))
+	if f != "09:05" {
+		t.Errorf("unexpected time: %s", f)
+	}
+
+	v, err := f.On(NewDate(2020, time.January, 2), tokyo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !v.Equal(time.Date(2020, 1, 2, 0, 5, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time: %s", v)
+	}
+}
+
+func TestRepositoryLocation(t *testing.T) {
+	repo, _ := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Query.TotalCount && req.Query.limit == 0 {
+			return []byte(`{"totalCount": "1"}`), nil
+		}
+		return []byte(`{
+			"records": [
+				{
+					"$id": {"type": "__ID__", "value": "1"},
+					"日付": {"type": "DATE", "value": "2020-01-02"},
+					"日時": {"type": "DATETIME", "value": "2020-01-01T15:30:00Z"},
+					"更新日時": {"type": "UPDATED_TIME", "value": "2020-01-01T15:30:00Z"}
+				}
+			]
+		}`), nil
+	})
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+	repo.Location = tokyo
+
+	rs, err := repo.ReadRecords(context.Background(), NewQuery(1))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	f := rs[0].Fields
+	if v := f["日時"].(DateTimeField); v.Value.Location() != tokyo || v.Date(nil) != (Date{2020, time.January, 2}) {
+		t.Errorf("unexpected 日時: %v", v)
+	}
+	if v := f["更新日時"].(DateTimeField); v.Value.Location() != tokyo {
+		t.Errorf("unexpected 更新日時: %v", v)
+	}
+	if v := f["日付"].(DateField); v.String() != "2020-01-02" {
+		t.Errorf("unexpected 日付: %v", v)
+	}
+
+	// 送信時はオフセット付きの RFC3339
+	data, err := json.Marshal(writeFields(f))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected := []byte(`{
+		"日付": {"value": "2020-01-02"},
+		"日時": {"value": "2020-01-02T00:30:00+09:00"},
+		"更新日時": {"value": "2020-01-02T00:30:00+09:00"}
+	}`)
+	if !jsonEqual(expected, data) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(data))
+	}
+}
@@ -0,0 +1,125 @@
+package kintone
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+// Decimal は kintone の数値を誤差なく保持する10進数
+// kintone から受け取った文字列をそのまま保持するため、"1.50" や "-1e3" も同じ表現で送り返す
+// ゼロ値は値が空であることを表す
+type Decimal struct {
+	s string
+}
+
+var decimalPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// ParseDecimal は数値の文字列を Decimal に変換する
+// 空文字の場合は空の Decimal を返す
+func ParseDecimal(s string) (Decimal, error) {
+	if s == "" {
+		return Decimal{}, nil
+	}
+	if !decimalPattern.MatchString(s) {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	return Decimal{s}, nil
+}
+
+// MustParseDecimal は ParseDecimal に失敗した場合 panic する
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewDecimalFromInt ...
+func NewDecimalFromInt(i int64) Decimal {
+	return Decimal{strconv.FormatInt(i, 10)}
+}
+
+// NewDecimalFromFloat は f を最短の10進数表現で保持する
+func NewDecimalFromFloat(f float64) Decimal {
+	return Decimal{strconv.FormatFloat(f, 'f', -1, 64)}
+}
+
+// NewDecimalFromRat は r を小数点以下 prec 桁に丸めて保持する
+func NewDecimalFromRat(r *big.Rat, prec int) Decimal {
+	return Decimal{r.FloatString(prec)}
+}
+
+func (d Decimal) String() string {
+	return d.s
+}
+
+// IsNull は値が空かどうかを返す
+func (d Decimal) IsNull() bool {
+	return d.s == ""
+}
+
+// Rat は値を big.Rat で返す。空の場合は 0 を返す
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat)
+	if d.s == "" {
+		return r
+	}
+	r.SetString(d.s)
+	return r
+}
+
+// Int64 は値が整数で int64 の範囲に収まる場合に値を返す
+func (d Decimal) Int64() (int64, error) {
+	r := d.Rat()
+	if !r.IsInt() {
+		return 0, fmt.Errorf("%s is not an integer", d.s)
+	}
+	n := r.Num()
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("%s overflows int64", d.s)
+	}
+	return n.Int64(), nil
+}
+
+// Float64 は最も近い float64 の値を返す
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Cmp は d と x を数値として比較する
+func (d Decimal) Cmp(x Decimal) int {
+	return d.Rat().Cmp(x.Rat())
+}
+
+// Equal は d と x が数値として等しいかを返す（"1.50" と "1.5" は等しい）
+func (d Decimal) Equal(x Decimal) bool {
+	if d.s == "" || x.s == "" {
+		return d.s == x.s
+	}
+	return d.Cmp(x) == 0
+}
+
+// MarshalJSON ...
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.s)
+}
+
+// UnmarshalJSON ...
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,182 @@
+package kintone
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		input string
+		ok    bool
+	}{
+		{"12.5", true},
+		{"-12.50", true},
+		{"1e3", true},
+		{"-1.5E-2", true},
+		{".5", true},
+		{"+3", true},
+		{"", true},
+		{"1,000", false},
+		{"abc", false},
+		{"1/2", false},
+		{"0x10", false},
+	}
+
+	for _, test := range tests {
+		d, err := ParseDecimal(test.input)
+		if test.ok != (err == nil) {
+			t.Errorf("input: %s, err: %v", test.input, err)
+			continue
+		}
+		if test.ok && d.String() != test.input {
+			t.Errorf("expected: %s, actual: %s", test.input, d.String())
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	d := MustParseDecimal("-1.5e1")
+	if d.Float64() != -15 {
+		t.Errorf("expected: -15, actual: %v", d.Float64())
+	}
+
+	i, err := d.Int64()
+	if err != nil || i != -15 {
+		t.Errorf("expected: -15, actual: %d, %v", i, err)
+	}
+
+	_, err = MustParseDecimal("12.5").Int64()
+	if err == nil {
+		t.Error("expected error")
+	}
+
+	if !MustParseDecimal("1.50").Equal(MustParseDecimal("1.5")) {
+		t.Error("1.50 should equal 1.5")
+	}
+	if MustParseDecimal("0").Equal(Decimal{}) {
+		t.Error("0 should not equal empty")
+	}
+	if MustParseDecimal("0.1").Cmp(MustParseDecimal("1e-2")) != 1 {
+		t.Error("0.1 should be greater than 1e-2")
+	}
+}
+
+func TestUnmarshalNumberRecord(t *testing.T) {
+	data := []byte(`
+		{
+			"整数": {"type": "NUMBER", "value": "20"},
+			"小数": {"type": "NUMBER", "value": "12.50"},
+			"指数": {"type": "NUMBER", "value": "-1e3"},
+			"大きい数": {"type": "NUMBER", "value": "99999999999999999999"}
+		}
+	`)
+
+	var r Record
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if v, ok := r.Fields["整数"].(NumberField); !ok || v != 20 {
+		t.Errorf("unexpected: %#v", r.Fields["整数"])
+	}
+	for _, code := range []string{"小数", "指数", "大きい数"} {
+		if _, ok := r.Fields[code].(DecimalField); !ok {
+			t.Errorf("%s: unexpected: %#v", code, r.Fields[code])
+		}
+	}
+
+	actual, err := json.Marshal(r.Fields)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []byte(`
+		{
+			"整数": {"value": "20"},
+			"小数": {"value": "12.50"},
+			"指数": {"value": "-1e3"},
+			"大きい数": {"value": "99999999999999999999"}
+		}
+	`)
+	if !jsonEqual(expected, actual) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(actual))
+	}
+}
+
+func TestCalcFieldParse(t *testing.T) {
+	f, err := CalcField("1.25").Parse(CalcFormatNumberDigit)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if v, ok := f.(DecimalField); !ok || v.String() != "1.25" {
+		t.Errorf("unexpected: %#v", f)
+	}
+
+	f, err = CalcField("2014-02-16T08:57:00Z").Parse(CalcFormatDateTime)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, ok := f.(DateTimeField); !ok {
+		t.Errorf("unexpected: %#v", f)
+	}
+
+	d, err := CalcField("5400").Duration()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if d != 90*time.Minute {
+		t.Errorf("expected: %s, actual: %s", 90*time.Minute, d)
+	}
+
+	_, err = CalcField("1").Parse("UNKNOWN")
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMarshalDecimal(t *testing.T) {
+	type item struct {
+		Price  Decimal `kintone:"price"`
+		Rate   float64 `kintone:"rate"`
+		Amount int     `kintone:"amount"`
+	}
+
+	r, err := Marshal(item{Price: MustParseDecimal("12.50"), Rate: 0.1, Amount: 3})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	actual, err := json.Marshal(r.Fields)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected := []byte(`{"price":{"value":"12.50"},"rate":{"value":"0.1"},"amount":{"value":"3"}}`)
+	if !jsonEqual(expected, actual) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(actual))
+		return
+	}
+
+	var v item
+	err = Unmarshal(&Record{Fields: Fields{
+		"price":  DecimalField{MustParseDecimal("1e2")},
+		"rate":   DecimalField{MustParseDecimal("0.25")},
+		"amount": DecimalField{MustParseDecimal("4.0")},
+	}}, &v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if v.Price.String() != "1e2" || v.Rate != 0.25 || v.Amount != 4 {
+		t.Errorf("unexpected: %+v", v)
+	}
+}
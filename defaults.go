@@ -0,0 +1,150 @@
+package kintone
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ApplyDefaults はレコードに含まれないフィールドにフォームの初期値を設定する
+// 「現在の日付・時刻を初期値にする」設定の日付・時刻・日時フィールドには now を使う
+// ログインユーザーなど関数で指定された初期値は kintone 側で設定されるため扱わない
+// ID の無いサブテーブルの行にも初期値を設定する
+func ApplyDefaults(form FormFields, r *Record, now time.Time) {
+	if r.Fields == nil {
+		r.Fields = make(Fields)
+	}
+
+	for _, code := range sortedFormCodes(form) {
+		ff := form[code]
+
+		if ff.Type == FieldTypeSubtable {
+			if t, ok := r.Fields[code].(TableField); ok {
+				for _, row := range t {
+					if row.ID == "" {
+						ApplyDefaults(ff.Fields, row, now)
+					}
+				}
+			}
+			continue
+		}
+
+		if _, ok := r.Fields[code]; ok {
+			continue
+		}
+		if f := defaultField(ff, now); f != nil {
+			r.Fields[code] = f
+		}
+	}
+}
+
+// defaultField はフォームの初期値を返す。初期値が無い場合は nil を返す
+func defaultField(ff *FormField, now time.Time) Field {
+	if ff.DefaultTime {
+		switch ff.Type {
+		case FieldTypeDate:
+			return DateField{DateOf(now)}
+		case FieldTypeTime:
+			return TimeFieldOf(now)
+		case FieldTypeDateTime:
+			return DateTimeField{Value: now}
+		}
+	}
+
+	switch v := ff.DefaultValue.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		f, err := NewFieldValue(ff.Type, v)
+		if err != nil {
+			return nil
+		}
+		return f
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		switch ff.Type {
+		case FieldTypeUsers, FieldTypeOrganization, FieldTypeGroup:
+			return defaultEntities(ff.Type, v)
+		}
+		var ss []string
+		for _, s := range v {
+			if s, ok := s.(string); ok {
+				ss = append(ss, s)
+			}
+		}
+		f, err := NewFieldValue(ff.Type, ss)
+		if err != nil {
+			return nil
+		}
+		return f
+	}
+	return nil
+}
+
+// defaultEntities はユーザー・組織・グループ選択の初期値を返す
+// 初期値は {"code": "...", "type": "USER"} の形式
+func defaultEntities(fieldType string, v []interface{}) Field {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var entities []*Entity
+	if err := json.Unmarshal(data, &entities); err != nil {
+		return nil
+	}
+
+	want := map[string]string{
+		FieldTypeUsers:        "USER",
+		FieldTypeOrganization: "ORGANIZATION",
+		FieldTypeGroup:        "GROUP",
+	}[fieldType]
+
+	var codes []string
+	for _, e := range entities {
+		// FUNCTION（ログインユーザーなど）は kintone が設定する
+		if e.Type == want {
+			codes = append(codes, e.Code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+
+	f, err := NewFieldValue(fieldType, codes)
+	if err != nil {
+		return nil
+	}
+	return f
+}
+
+// withDefaults はフィールドをコピーしたレコードに初期値を設定して返す
+// 呼び出し元のレコードは変更しない
+func withDefaults(form FormFields, rs []*Record, now time.Time) []*Record {
+	out := make([]*Record, len(rs))
+	for i, r := range rs {
+		fs := make(Fields, len(r.Fields))
+		for code, f := range r.Fields {
+			if t, ok := f.(TableField); ok {
+				rows := make(TableField, len(t))
+				for j, row := range t {
+					rows[j] = &Record{ID: row.ID, Fields: copyFields(row.Fields)}
+				}
+				f = rows
+			}
+			fs[code] = f
+		}
+		out[i] = &Record{ID: r.ID, Fields: fs}
+		ApplyDefaults(form, out[i], now)
+	}
+	return out
+}
+
+func copyFields(fs Fields) Fields {
+	out := make(Fields, len(fs))
+	for code, f := range fs {
+		out[code] = f
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+var testDefaultsForm = FormFields{
+	"名前": {Code: "名前", Type: FieldTypeSingleLineText, DefaultValue: "名無し"},
+	"数量": {Code: "数量", Type: FieldTypeNumber, DefaultValue: "1"},
+	"メモ": {Code: "メモ", Type: FieldTypeMultiLineText, DefaultValue: ""},
+	"区分": {Code: "区分", Type: FieldTypeRadioButton, DefaultValue: "通常"},
+	"タグ": {Code: "タグ", Type: FieldTypeCheckBox, DefaultValue: []interface{}{"x", "y"}},
+	"日付": {Code: "日付", Type: FieldTypeDate, DefaultTime: true},
+	"期限": {Code: "期限", Type: FieldTypeDate, DefaultValue: "2020-04-01"},
+	"日時": {Code: "日時", Type: FieldTypeDateTime, DefaultTime: true},
+	"担当": {Code: "担当", Type: FieldTypeUsers, DefaultValue: []interface{}{
+		map[string]interface{}{"code": "sato", "type": "USER"},
+		map[string]interface{}{"code": "LOGINUSER()", "type": "FUNCTION"},
+	}},
+	"組織": {Code: "組織", Type: FieldTypeOrganization, DefaultValue: []interface{}{
+		map[string]interface{}{"code": "PRIMARY_ORGANIZATION()", "type": "FUNCTION"},
+	}},
+	"明細": {Code: "明細", Type: FieldTypeSubtable, Fields: FormFields{
+		"商品": {Code: "商品", Type: FieldTypeSingleLineText, DefaultValue: "pen"},
+	}},
+}
+
+func TestApplyDefaults(t *testing.T) {
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+	now := time.Date(2020, 5, 1, 8, 30, 0, 0, tokyo)
+
+	r := &Record{Fields: Fields{
+		"名前": SingleLineTextField("佐藤"),
+		"明細": TableField{
+			{ID: "1", Fields: Fields{}},
+			{Fields: Fields{}},
+		},
+	}}
+	ApplyDefaults(testDefaultsForm, r, now)
+
+	tests := []struct {
+		code     string
+		expected Field
+	}{
+		{"名前", SingleLineTextField("佐藤")},
+		{"区分", RadioButtonField("通常")},
+		{"日付", DateField{NewDate(2020, 5, 1)}},
+		{"期限", DateField{NewDate(2020, 4, 1)}},
+		{"日時", DateTimeField{Value: now}},
+	}
+	for _, test := range tests {
+		if actual := r.Fields[test.code]; !FieldEqual(test.expected, actual) {
+			t.Errorf("%s: expected: %v, actual: %v", test.code, test.expected, actual)
+		}
+	}
+
+	if n, err := r.Fields.Number("数量"); err != nil || n.String() != "1" {
+		t.Errorf("unexpected number: %v, %v", n, err)
+	}
+	if ss, err := r.Fields.Strings("タグ"); err != nil || len(ss) != 2 {
+		t.Errorf("unexpected strings: %v, %v", ss, err)
+	}
+	if us, err := r.Fields.Users("担当"); err != nil || len(us) != 1 || us[0].Code != "sato" {
+		t.Errorf("unexpected users: %v, %v", us, err)
+	}
+	for _, code := range []string{"メモ", "組織"} {
+		if f, ok := r.Fields[code]; ok {
+			t.Errorf("%s should not be set: %v", code, f)
+		}
+	}
+
+	rows := r.Fields["明細"].(TableField)
+	if _, ok := rows[0].Fields["商品"]; ok {
+		t.Errorf("existing row should not be changed: %v", rows[0].Fields)
+	}
+	if rows[1].Fields["商品"] != SingleLineTextField("pen") {
+		t.Errorf("unexpected row: %v", rows[1].Fields)
+	}
+}
+
+func TestRepositoryApplyDefaults(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Path == APIEndpointFormField {
+			return []byte(`{"properties": {
+				"名前": {"type": "SINGLE_LINE_TEXT", "code": "名前", "defaultValue": "名無し"},
+				"区分": {"type": "RADIO_BUTTON", "code": "区分", "defaultValue": "通常"}
+			}}`), nil
+		}
+		return []byte(`{"ids": ["1"], "revisions": ["1"]}`), nil
+	})
+	repo.ApplyDefaults = true
+
+	r := &Record{Fields: Fields{"名前": SingleLineTextField("佐藤")}}
+	if _, err := repo.AddRecords(context.Background(), 1, r); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(r.Fields) != 1 {
+		t.Errorf("record should not be changed: %v", r.Fields)
+	}
+
+	var body struct {
+		Records []map[string]json.RawMessage `json:"records"`
+	}
+	json.Unmarshal(c.requests[len(c.requests)-1].Body, &body)
+	if len(body.Records) != 1 || !jsonEqual([]byte(`{"value": "通常"}`), body.Records[0]["区分"]) {
+		t.Errorf("unexpected body: %s", c.requests[len(c.requests)-1].Body)
+	}
+}
@@ -0,0 +1,139 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// 運用環境への反映状況
+const (
+	DeployStatusProcessing = "PROCESSING"
+	DeployStatusSuccess    = "SUCCESS"
+	DeployStatusFail       = "FAIL"
+	DeployStatusCancel     = "CANCEL"
+)
+
+// DeployPollInterval は運用環境への反映状況を確認する間隔
+var DeployPollInterval = time.Second
+
+// DeployApp は運用環境に反映するアプリ
+// Revision が空の場合は revision を確認しない
+type DeployApp struct {
+	App      int    `json:"app"`
+	Revision string `json:"revision,omitempty"`
+}
+
+// DeployStatus はアプリの運用環境への反映状況
+type DeployStatus struct {
+	App    int    `json:"app,string"`
+	Status string `json:"status"`
+}
+
+// DeployError は運用環境への反映に失敗、またはキャンセルされたアプリ
+type DeployError struct {
+	Statuses []*DeployStatus
+}
+
+func (e *DeployError) Error() string {
+	msgs := make([]string, len(e.Statuses))
+	for i, s := range e.Statuses {
+		msgs[i] = fmt.Sprintf("app %d: %s", s.App, s.Status)
+	}
+	return "kintone: deploy failed: " + strings.Join(msgs, ", ")
+}
+
+// DeployApps はアプリの設定を運用環境に反映し、反映が終わるまで待つ
+// revert が true の場合は設定の変更を取り消す
+// 反映に失敗、またはキャンセルされたアプリがある場合は *DeployError を返す
+func (repo *Repository) DeployApps(ctx context.Context, apps []*DeployApp, revert bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	body, err := json.Marshal(struct {
+		Apps   []*DeployApp `json:"apps"`
+		Revert bool         `json:"revert"`
+	}{apps, revert})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Client.post(APIEndpointPreviewDeploy, body)
+	if err != nil {
+		return errors.Wrap(err, "deploy failed")
+	}
+
+	ids := make([]int, len(apps))
+	for i, a := range apps {
+		ids[i] = a.App
+	}
+	return repo.WaitDeploy(ctx, ids...)
+}
+
+// ReadDeployStatus はアプリの運用環境への反映状況を返す
+func (repo *Repository) ReadDeployStatus(appIDs ...int) ([]*DeployStatus, error) {
+	body, err := json.Marshal(struct {
+		Apps []int `json:"apps"`
+	}{appIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := repo.Client.getWithBody(APIEndpointPreviewDeploy, body)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := struct {
+		Apps []*DeployStatus `json:"apps"`
+	}{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return raw.Apps, nil
+}
+
+// WaitDeploy は全てのアプリの運用環境への反映が終わるまで DeployPollInterval ごとに反映状況を確認する
+// 反映に失敗、またはキャンセルされたアプリがある場合は *DeployError を返す
+func (repo *Repository) WaitDeploy(ctx context.Context, appIDs ...int) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		statuses, err := repo.ReadDeployStatus(appIDs...)
+		if err != nil {
+			return errors.Wrap(err, "read deploy status failed")
+		}
+
+		var processing bool
+		var failed []*DeployStatus
+		for _, s := range statuses {
+			switch s.Status {
+			case DeployStatusProcessing:
+				processing = true
+			case DeployStatusFail, DeployStatusCancel:
+				failed = append(failed, s)
+			}
+		}
+
+		if !processing {
+			if len(failed) > 0 {
+				return &DeployError{failed}
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(DeployPollInterval):
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package kintone
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUpdateFormFields(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"revision": "4"}`), nil
+	})
+
+	fs := FormFields{"名前": {Code: "名前", Label: "氏名", Type: FieldTypeSingleLineText, MaxLength: "10"}}
+	revision, err := repo.UpdateFormFields(1, fs, "3")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if revision != "4" {
+		t.Errorf("expected: 4, actual: %s", revision)
+	}
+
+	expected := []byte(`{
+		"app": 1,
+		"revision": "3",
+		"properties": {
+			"名前": {
+				"code": "名前",
+				"label": "氏名",
+				"type": "SINGLE_LINE_TEXT",
+				"maxLength": "10",
+				"noLabel": false,
+				"required": false,
+				"unique": false,
+				"defaultNowValue": false,
+				"hideExpression": false,
+				"digit": false,
+				"openGroup": false,
+				"enabled": false
+			}
+		}
+	}`)
+	req := c.requests[0]
+	if req.Method != "PUT" || req.Path != APIEndpointPreviewFormField || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s %s", expected, req.Method, req.Body)
+	}
+
+	if _, err := repo.DeleteFormFields(1, []string{"名前"}, ""); err != nil {
+		t.Error(err)
+		return
+	}
+	expected = []byte(`{"app": 1, "fields": ["名前"]}`)
+	if req := c.requests[1]; req.Method != "DELETE" || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s %s", expected, req.Method, req.Body)
+	}
+}
+
+func TestDeployApps(t *testing.T) {
+	DeployPollInterval = 0
+
+	var polls int
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Method == "POST" {
+			return []byte(`{}`), nil
+		}
+		polls++
+		if polls < 3 {
+			return []byte(`{"apps": [{"app": "1", "status": "SUCCESS"}, {"app": "2", "status": "PROCESSING"}]}`), nil
+		}
+		return []byte(`{"apps": [{"app": "1", "status": "SUCCESS"}, {"app": "2", "status": "FAIL"}]}`), nil
+	})
+
+	err := repo.DeployApps(context.Background(), []*DeployApp{{App: 1, Revision: "4"}, {App: 2}}, false)
+	var de *DeployError
+	if !errors.As(err, &de) {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if len(de.Statuses) != 1 || de.Statuses[0].App != 2 {
+		t.Errorf("unexpected statuses: %v", de.Statuses)
+	}
+
+	expected := []byte(`{"apps": [{"app": 1, "revision": "4"}, {"app": 2}], "revert": false}`)
+	if !jsonEqual(expected, c.requests[0].Body) {
+		t.Errorf("expected: %s, actual: %s", expected, c.requests[0].Body)
+	}
+	if len(c.requests) != 4 || !jsonEqual([]byte(`{"apps": [1, 2]}`), c.requests[1].Body) {
+		t.Errorf("unexpected requests: %d", len(c.requests))
+	}
+}
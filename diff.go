@@ -0,0 +1,244 @@
+package kintone
+
+import (
+	"reflect"
+	"sort"
+)
+
+// FieldChange はフィールドの値の変更
+type FieldChange struct {
+	Code string
+	Old  Field // フィールドが追加された場合は nil
+	New  Field // フィールドが削除された場合は nil
+}
+
+// Diff は old から new への変更をフィールドコード順に返す
+// 値の比較は FieldEqual で行う
+func Diff(old, new *Record) []*FieldChange {
+	var oldFields, newFields Fields
+	if old != nil {
+		oldFields = old.Fields
+	}
+	if new != nil {
+		newFields = new.Fields
+	}
+
+	codes := make(map[string]bool)
+	for code := range oldFields {
+		codes[code] = true
+	}
+	for code := range newFields {
+		codes[code] = true
+	}
+
+	var changes []*FieldChange
+	for code := range codes {
+		o, n := oldFields[code], newFields[code]
+		if FieldEqual(o, n) {
+			continue
+		}
+		changes = append(changes, &FieldChange{Code: code, Old: o, New: n})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Code < changes[j].Code
+	})
+
+	return changes
+}
+
+// Patch は old を new に更新するために必要なフィールドのみを持つレコードを返す
+// new に含まれないフィールドや、値を指定できないフィールドは更新しない
+// サブテーブルは行が1つでも変わっていればテーブル全体を送信する
+// 更新が必要ない場合は nil を返す
+func Patch(old, new *Record) *Record {
+	if new == nil {
+		return nil
+	}
+
+	fs := make(Fields)
+	for _, c := range Diff(old, new) {
+		if c.New == nil || !isWritableField(c.New) {
+			continue
+		}
+		fs[c.Code] = c.New
+	}
+
+	if len(fs) == 0 {
+		return nil
+	}
+
+	id := new.ID
+	if id == "" && old != nil {
+		id = old.ID
+	}
+	return &Record{ID: id, Fields: fs}
+}
+
+// PatchRecords は ID が一致するレコードごとに Patch を行い、更新が必要なレコードのみを返す
+// olds に存在しない ID のレコードは全てのフィールドを更新する
+func PatchRecords(olds, news []*Record) []*Record {
+	byID := make(map[string]*Record, len(olds))
+	for _, r := range olds {
+		byID[r.ID] = r
+	}
+
+	var rs []*Record
+	for _, r := range news {
+		if p := Patch(byID[r.ID], r); p != nil {
+			rs = append(rs, p)
+		}
+	}
+	return rs
+}
+
+// FieldEqual はフィールドの値が等しいかどうかを返す
+//   - 値が空のフィールド同士は等しい
+//   - 数値は "1.50" と "1.5" のように表記が違っても等しい
+//   - チェックボックスなどの複数選択、ユーザーなどの選択は順序を問わない
+//   - 日時はタイムゾーンが違っても同じ時刻であれば等しい
+//   - サブテーブルは行 ID で行を対応させて比較する
+func FieldEqual(a, b Field) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if IsNull(a) && IsNull(b) {
+		return true
+	}
+
+	if da, ok := numberValue(a); ok {
+		db, ok := numberValue(b)
+		return ok && da.Equal(db)
+	}
+
+	switch a := a.(type) {
+	case CheckBoxField:
+		b, ok := b.(CheckBoxField)
+		return ok && stringSetEqual(a, b)
+	case MultiSelectField:
+		b, ok := b.(MultiSelectField)
+		return ok && stringSetEqual(a, b)
+	case CategoryField:
+		b, ok := b.(CategoryField)
+		return ok && stringSetEqual(a, b)
+	case DateTimeField:
+		b, ok := b.(DateTimeField)
+		return ok && a.Value.Equal(b.Value)
+	case []*UserField:
+		b, ok := b.([]*UserField)
+		return ok && stringSetEqual(userCodes(a), userCodes(b))
+	case AssigneeField:
+		b, ok := b.(AssigneeField)
+		return ok && stringSetEqual(userCodes(a), userCodes(b))
+	case []*OrganizationField:
+		b, ok := b.([]*OrganizationField)
+		if !ok {
+			return false
+		}
+		ac, bc := make([]string, len(a)), make([]string, len(b))
+		for i, o := range a {
+			ac[i] = o.Code
+		}
+		for i, o := range b {
+			bc[i] = o.Code
+		}
+		return stringSetEqual(ac, bc)
+	case []*GroupField:
+		b, ok := b.([]*GroupField)
+		if !ok {
+			return false
+		}
+		ac, bc := make([]string, len(a)), make([]string, len(b))
+		for i, g := range a {
+			ac[i] = g.Code
+		}
+		for i, g := range b {
+			bc[i] = g.Code
+		}
+		return stringSetEqual(ac, bc)
+	case *UserField:
+		b, ok := b.(*UserField)
+		return ok && a.Code == b.Code
+	case FileField:
+		b, ok := b.(FileField)
+		if !ok {
+			return false
+		}
+		ak, bk := make([]string, len(a)), make([]string, len(b))
+		for i, f := range a {
+			ak[i] = f.FileKey
+		}
+		for i, f := range b {
+			bk[i] = f.FileKey
+		}
+		return stringSetEqual(ak, bk)
+	case TableField:
+		b, ok := b.(TableField)
+		return ok && tableEqual(a, b)
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func numberValue(f Field) (Decimal, bool) {
+	switch f.(type) {
+	case NumberField, NullNumberField, DecimalField:
+		d, err := fieldDecimal(f)
+		return d, err == nil
+	}
+	return Decimal{}, false
+}
+
+func userCodes(us []*UserField) []string {
+	codes := make([]string, len(us))
+	for i, u := range us {
+		codes[i] = u.Code
+	}
+	return codes
+}
+
+func stringSetEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := make(map[string]int, len(a))
+	for _, s := range a {
+		count[s]++
+	}
+	for _, s := range b {
+		if count[s] == 0 {
+			return false
+		}
+		count[s]--
+	}
+	return true
+}
+
+// tableEqual は b の各行が a の同じ ID の行と等しいかどうかを返す
+// ID の無い行は追加された行として扱う。行の比較は b の行に含まれるフィールドのみで行う
+func tableEqual(a, b TableField) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	rows := make(map[string]*Record, len(a))
+	for _, r := range a {
+		rows[r.ID] = r
+	}
+
+	for _, r := range b {
+		if r.ID == "" {
+			return false
+		}
+		old, ok := rows[r.ID]
+		if !ok {
+			return false
+		}
+		for code, f := range r.Fields {
+			if !FieldEqual(old.Fields[code], f) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package kintone
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFieldEqual(t *testing.T) {
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+
+	tests := []struct {
+		a, b     Field
+		expected bool
+	}{
+		{SingleLineTextField("a"), SingleLineTextField("a"), true},
+		{SingleLineTextField("a"), SingleLineTextField("b"), false},
+		{SingleLineTextField(""), nil, false},
+		{DateField{}, NewNullField(FieldTypeDate), true},
+		{NumberField(1), DecimalField{MustParseDecimal("1.0")}, true},
+		{NumberField(0), DecimalField{}, false},
+		{CheckBoxField{"a", "b"}, CheckBoxField{"b", "a"}, true},
+		{CheckBoxField{"a", "b"}, CheckBoxField{"a", "a"}, false},
+		{CheckBoxField{"a"}, MultiSelectField{"a"}, false},
+		{
+			DateTimeField{Value: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			DateTimeField{Value: time.Date(2020, 1, 1, 9, 0, 0, 0, tokyo)},
+			true,
+		},
+		{[]*UserField{{Code: "a", Name: "A"}, {Code: "b"}}, []*UserField{{Code: "b"}, {Code: "a"}}, true},
+		{
+			TableField{{ID: "1", Fields: Fields{"x": SingleLineTextField("a"), "y": NumberField(1)}}, {ID: "2", Fields: Fields{}}},
+			TableField{{ID: "2", Fields: Fields{}}, {ID: "1", Fields: Fields{"x": SingleLineTextField("a")}}},
+			true,
+		},
+		{
+			TableField{{ID: "1", Fields: Fields{"x": SingleLineTextField("a")}}},
+			TableField{{ID: "1", Fields: Fields{"x": SingleLineTextField("b")}}},
+			false,
+		},
+		{
+			TableField{{ID: "1", Fields: Fields{"x": SingleLineTextField("a")}}},
+			TableField{{Fields: Fields{"x": SingleLineTextField("a")}}},
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		if actual := FieldEqual(test.a, test.b); actual != test.expected {
+			t.Errorf("%d: expected: %v, actual: %v", i, test.expected, actual)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := &Record{ID: "1", Fields: Fields{
+		"$revision": RevisionField("3"),
+		"name":      SingleLineTextField("a"),
+		"tags":      CheckBoxField{"x", "y"},
+		"count":     NumberField(1),
+	}}
+	new := &Record{Fields: Fields{
+		"name":  SingleLineTextField("b"),
+		"tags":  CheckBoxField{"y", "x"},
+		"count": DecimalField{MustParseDecimal("1")},
+		"note":  MultiLineTextField("memo"),
+	}}
+
+	changes := Diff(old, new)
+	expected := []*FieldChange{
+		{Code: "$revision", Old: RevisionField("3")},
+		{Code: "name", Old: SingleLineTextField("a"), New: SingleLineTextField("b")},
+		{Code: "note", New: MultiLineTextField("memo")},
+	}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("expected: %v, actual: %v", expected, changes)
+	}
+
+	p := Patch(old, new)
+	if p == nil || p.ID != "1" || len(p.Fields) != 2 || p.Fields["name"] != SingleLineTextField("b") {
+		t.Errorf("unexpected patch: %#v", p)
+	}
+
+	if p := Patch(old, &Record{ID: "1", Fields: Fields{"name": SingleLineTextField("a"), "計算": CalcField("1")}}); p != nil {
+		t.Errorf("unexpected patch: %#v", p)
+	}
+}
+
+func TestPatchRecords(t *testing.T) {
+	olds := []*Record{
+		{ID: "1", Fields: Fields{"name": SingleLineTextField("a")}},
+		{ID: "2", Fields: Fields{"name": SingleLineTextField("b")}},
+	}
+	news := []*Record{
+		{ID: "1", Fields: Fields{"name": SingleLineTextField("a")}},
+		{ID: "2", Fields: Fields{"name": SingleLineTextField("c")}},
+		{ID: "3", Fields: Fields{"name": SingleLineTextField("d")}},
+	}
+
+	rs := PatchRecords(olds, news)
+	if len(rs) != 2 || rs[0].ID != "2" || rs[1].ID != "3" {
+		t.Errorf("unexpected records: %v", rs)
+	}
+}
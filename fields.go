@@ -0,0 +1,499 @@
+package kintone
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// FieldError はフィールドの値の取得・設定で型が合わない場合のエラー
+type FieldError struct {
+	Code string
+	Type string // フィールドのタイプ。フィールドが存在しない場合は空
+	Want string // 取得・設定しようとした値の型
+}
+
+func (e *FieldError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("kintone: field %s not found", e.Code)
+	}
+	return fmt.Sprintf("kintone: field %s is %s, cannot be used as %s", e.Code, e.Type, e.Want)
+}
+
+// fieldTypeName はエラーメッセージ用のフィールドタイプを返す
+func fieldTypeName(f Field) string {
+	if t := FieldTypeOf(f); t != "" {
+		return t
+	}
+	return fmt.Sprintf("%T", f)
+}
+
+func (fs Fields) field(code, want string) (Field, error) {
+	f, ok := fs[code]
+	if !ok || f == nil {
+		return nil, &FieldError{Code: code, Want: want}
+	}
+	return f, nil
+}
+
+func (fs Fields) typeError(code, want string) error {
+	return &FieldError{Code: code, Type: fieldTypeName(fs[code]), Want: want}
+}
+
+//+Getter
+
+// String は文字列として扱えるフィールドの値を返す
+func (fs Fields) String(code string) (string, error) {
+	f, err := fs.field(code, "string")
+	if err != nil {
+		return "", err
+	}
+	switch f := f.(type) {
+	case SingleLineTextField:
+		return string(f), nil
+	case MultiLineTextField:
+		return string(f), nil
+	case RichTextField:
+		return string(f), nil
+	case LinkField:
+		return string(f), nil
+	case RadioButtonField:
+		return string(f), nil
+	case SingleSelectField:
+		return f.Value, nil
+	case StatusField:
+		return string(f), nil
+	case RecordNumberField:
+		return string(f), nil
+	case IDField:
+		return string(f), nil
+	case RevisionField:
+		return string(f), nil
+	case CalcField:
+		return string(f), nil
+	case TimeField:
+		return string(f), nil
+	}
+	return "", fs.typeError(code, "string")
+}
+
+// Number は数値フィールド、計算フィールドの値を返す
+func (fs Fields) Number(code string) (Decimal, error) {
+	f, err := fs.field(code, "number")
+	if err != nil {
+		return Decimal{}, err
+	}
+	switch f.(type) {
+	case NumberField, NullNumberField, DecimalField, CalcField:
+		d, err := fieldDecimal(f)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("kintone: field %s: %s", code, err)
+		}
+		return d, nil
+	}
+	return Decimal{}, fs.typeError(code, "number")
+}
+
+// Time は日時フィールドの値を返す
+// 日付フィールドの場合は UTC の 0 時を返す。値が空の場合はゼロ値を返す
+func (fs Fields) Time(code string) (time.Time, error) {
+	f, err := fs.field(code, "time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch f := f.(type) {
+	case DateTimeField:
+		return f.Value, nil
+	case DateField:
+		if f.IsNull() {
+			return time.Time{}, nil
+		}
+		return f.Value.In(time.UTC), nil
+	}
+	return time.Time{}, fs.typeError(code, "time")
+}
+
+// Date は日付フィールドの値を返す
+func (fs Fields) Date(code string) (Date, error) {
+	f, err := fs.field(code, "date")
+	if err != nil {
+		return Date{}, err
+	}
+	if f, ok := f.(DateField); ok {
+		return f.Value, nil
+	}
+	return Date{}, fs.typeError(code, "date")
+}
+
+// Strings はチェックボックス、複数選択、カテゴリーの値を返す
+func (fs Fields) Strings(code string) ([]string, error) {
+	f, err := fs.field(code, "strings")
+	if err != nil {
+		return nil, err
+	}
+	switch f := f.(type) {
+	case CheckBoxField:
+		return []string(f), nil
+	case MultiSelectField:
+		return []string(f), nil
+	case CategoryField:
+		return []string(f), nil
+	}
+	return nil, fs.typeError(code, "strings")
+}
+
+// Users はユーザー選択、作業者、作成者、更新者の値を返す
+func (fs Fields) Users(code string) ([]*UserField, error) {
+	f, err := fs.field(code, "users")
+	if err != nil {
+		return nil, err
+	}
+	switch f := f.(type) {
+	case []*UserField:
+		return f, nil
+	case AssigneeField:
+		return []*UserField(f), nil
+	case *UserField:
+		if f.IsNull() {
+			return nil, nil
+		}
+		return []*UserField{f}, nil
+	}
+	return nil, fs.typeError(code, "users")
+}
+
+// Organizations は組織選択の値を返す
+func (fs Fields) Organizations(code string) ([]*OrganizationField, error) {
+	f, err := fs.field(code, "organizations")
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := f.([]*OrganizationField); ok {
+		return f, nil
+	}
+	return nil, fs.typeError(code, "organizations")
+}
+
+// Groups はグループ選択の値を返す
+func (fs Fields) Groups(code string) ([]*GroupField, error) {
+	f, err := fs.field(code, "groups")
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := f.([]*GroupField); ok {
+		return f, nil
+	}
+	return nil, fs.typeError(code, "groups")
+}
+
+// Files は添付ファイルの値を返す
+func (fs Fields) Files(code string) (FileField, error) {
+	f, err := fs.field(code, "files")
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := f.(FileField); ok {
+		return f, nil
+	}
+	return nil, fs.typeError(code, "files")
+}
+
+// Table はサブテーブルの行を返す
+func (fs Fields) Table(code string) (TableField, error) {
+	f, err := fs.field(code, "table")
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := f.(TableField); ok {
+		return f, nil
+	}
+	return nil, fs.typeError(code, "table")
+}
+
+//-Getter
+
+//+Setter
+
+// 各 Setter はフィールドが既に存在する場合はそのタイプで、存在しない場合は既定のタイプで値を設定する
+// フォームの設定どおりのタイプで設定する場合は SetWithForm を使う
+
+// SetString は文字列を設定する。既定のタイプは SINGLE_LINE_TEXT
+func (fs Fields) SetString(code, v string) error {
+	return fs.set(code, FieldTypeSingleLineText, v)
+}
+
+// SetNumber は数値を設定する
+func (fs Fields) SetNumber(code string, v Decimal) error {
+	return fs.set(code, FieldTypeNumber, v)
+}
+
+// SetTime は日時を設定する。既定のタイプは DATETIME
+func (fs Fields) SetTime(code string, v time.Time) error {
+	return fs.set(code, FieldTypeDateTime, v)
+}
+
+// SetDate は日付を設定する
+func (fs Fields) SetDate(code string, v Date) error {
+	return fs.set(code, FieldTypeDate, v)
+}
+
+// SetStrings は複数の文字列を設定する。既定のタイプは CHECK_BOX
+func (fs Fields) SetStrings(code string, v []string) error {
+	return fs.set(code, FieldTypeCheckBox, v)
+}
+
+// SetUsers はユーザーをコードで設定する
+func (fs Fields) SetUsers(code string, codes ...string) error {
+	return fs.set(code, FieldTypeUsers, codes)
+}
+
+// SetOrganizations は組織をコードで設定する
+func (fs Fields) SetOrganizations(code string, codes ...string) error {
+	return fs.set(code, FieldTypeOrganization, codes)
+}
+
+// SetGroups はグループをコードで設定する
+func (fs Fields) SetGroups(code string, codes ...string) error {
+	return fs.set(code, FieldTypeGroup, codes)
+}
+
+// SetTable はサブテーブルの行を設定する
+func (fs Fields) SetTable(code string, rows ...*Record) error {
+	return fs.set(code, FieldTypeSubtable, TableField(rows))
+}
+
+// SetNull はフィールドの値を空にする
+func (fs Fields) SetNull(code string) error {
+	return fs.set(code, "", nil)
+}
+
+// SetWithForm はフォームの設定に従ったタイプで値を設定する
+// フォームに存在しないフィールドや、タイプに合わない値の場合はエラーを返す
+func (fs Fields) SetWithForm(form FormFields, code string, v interface{}) error {
+	ff, ok := form[code]
+	if !ok || ff == nil {
+		return &FieldError{Code: code, Want: fmt.Sprintf("%T", v)}
+	}
+	f, err := NewFieldValue(ff.Type, v)
+	if err != nil {
+		return &FieldError{Code: code, Type: ff.Type, Want: fmt.Sprintf("%T", v)}
+	}
+	fs[code] = f
+	return nil
+}
+
+func (fs Fields) set(code, defaultType string, v interface{}) error {
+	fieldType := defaultType
+	if f, ok := fs[code]; ok && f != nil {
+		fieldType = FieldTypeOf(f)
+		if fieldType == "" {
+			// 既存のフィールドの型からタイプを判定できない
+			return &FieldError{Code: code, Type: fieldTypeName(f), Want: fmt.Sprintf("%T", v)}
+		}
+	}
+	if fieldType == "" {
+		return &FieldError{Code: code, Want: fmt.Sprintf("%T", v)}
+	}
+
+	f, err := NewFieldValue(fieldType, v)
+	if err != nil {
+		return &FieldError{Code: code, Type: fieldType, Want: fmt.Sprintf("%T", v)}
+	}
+	fs[code] = f
+	return nil
+}
+
+// NewFieldValue は Go の値をフィールドタイプに対応する Field に変換する
+// v が nil の場合は空の値を返す
+func NewFieldValue(fieldType string, v interface{}) (Field, error) {
+	if v == nil {
+		if f := NewNullField(fieldType); f != nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("unsupported field type: %s", fieldType)
+	}
+
+	mismatch := fmt.Errorf("cannot use %T as %s", v, fieldType)
+
+	switch fieldType {
+	case FieldTypeSingleLineText, FieldTypeMultiLineText, FieldTypeRichText, FieldTypeLink,
+		FieldTypeRadioButton, FieldTypeSingleSelect:
+		s, ok := v.(string)
+		if !ok {
+			return nil, mismatch
+		}
+		switch fieldType {
+		case FieldTypeMultiLineText:
+			return MultiLineTextField(s), nil
+		case FieldTypeRichText:
+			return RichTextField(s), nil
+		case FieldTypeLink:
+			return LinkField(s), nil
+		case FieldTypeRadioButton:
+			return RadioButtonField(s), nil
+		case FieldTypeSingleSelect:
+			return SingleSelectField{s}, nil
+		}
+		return SingleLineTextField(s), nil
+
+	case FieldTypeNumber:
+		switch v := v.(type) {
+		case Decimal:
+			return DecimalField{v}, nil
+		case string:
+			d, err := ParseDecimal(v)
+			if err != nil {
+				return nil, err
+			}
+			return DecimalField{d}, nil
+		}
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return NumberField(rv.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			return NumberField(int64(rv.Uint())), nil
+		case reflect.Float32, reflect.Float64:
+			return DecimalField{NewDecimalFromFloat(rv.Float())}, nil
+		}
+
+	case FieldTypeDate:
+		switch v := v.(type) {
+		case Date:
+			return DateField{v}, nil
+		case time.Time:
+			if v.IsZero() {
+				return DateField{}, nil
+			}
+			return DateField{DateOf(v)}, nil
+		case string:
+			d, err := ParseDate(v)
+			if err != nil {
+				return nil, err
+			}
+			return DateField{d}, nil
+		}
+
+	case FieldTypeDateTime, FieldTypeCreatedDateTime, FieldTypeUpdatedDateTime:
+		var f DateTimeField
+		switch v := v.(type) {
+		case time.Time:
+			f = DateTimeField{Value: v}
+		case string:
+			if v != "" {
+				t, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					return nil, err
+				}
+				f = DateTimeField{Value: t}
+			}
+		default:
+			return nil, mismatch
+		}
+		if fieldType != FieldTypeDateTime {
+			f.fieldType = fieldType
+		}
+		return f, nil
+
+	case FieldTypeTime:
+		switch v := v.(type) {
+		case time.Time:
+			if v.IsZero() {
+				return TimeField(""), nil
+			}
+			return TimeFieldOf(v), nil
+		case string:
+			f := TimeField(v)
+			if v != "" {
+				if _, _, err := f.Clock(); err != nil {
+					return nil, err
+				}
+			}
+			return f, nil
+		}
+
+	case FieldTypeCheckBox, FieldTypeMultiSelect:
+		s, ok := v.([]string)
+		if !ok {
+			return nil, mismatch
+		}
+		if fieldType == FieldTypeMultiSelect {
+			return MultiSelectField(s), nil
+		}
+		return CheckBoxField(s), nil
+
+	case FieldTypeUsers:
+		switch v := v.(type) {
+		case []*UserField:
+			return v, nil
+		case []string:
+			users := make([]*UserField, len(v))
+			for i, code := range v {
+				users[i] = &UserField{Code: code}
+			}
+			return users, nil
+		}
+
+	case FieldTypeOrganization:
+		switch v := v.(type) {
+		case []*OrganizationField:
+			return v, nil
+		case []string:
+			orgs := make([]*OrganizationField, len(v))
+			for i, code := range v {
+				orgs[i] = &OrganizationField{Code: code}
+			}
+			return orgs, nil
+		}
+
+	case FieldTypeGroup:
+		switch v := v.(type) {
+		case []*GroupField:
+			return v, nil
+		case []string:
+			groups := make([]*GroupField, len(v))
+			for i, code := range v {
+				groups[i] = &GroupField{Code: code}
+			}
+			return groups, nil
+		}
+
+	case FieldTypeCreator, FieldTypeModifier:
+		var u UserField
+		switch v := v.(type) {
+		case *UserField:
+			u = *v
+		case string:
+			u = UserField{Code: v}
+		case []string:
+			if len(v) != 1 {
+				return nil, mismatch
+			}
+			u = UserField{Code: v[0]}
+		default:
+			return nil, mismatch
+		}
+		u.fieldType = fieldType
+		return &u, nil
+
+	case FieldTypeFile:
+		if f, ok := v.(FileField); ok {
+			return f, nil
+		}
+
+	case FieldTypeSubtable:
+		switch v := v.(type) {
+		case TableField:
+			return v, nil
+		case []*Record:
+			return TableField(v), nil
+		}
+
+	default:
+		return nil, fmt.Errorf("field type %s cannot be set", fieldType)
+	}
+
+	return nil, mismatch
+}
+
+//-Setter
@@ -0,0 +1,156 @@
+package kintone
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFieldsGetter(t *testing.T) {
+	data := []byte(`{
+		"文字列": {"type": "SINGLE_LINE_TEXT", "value": "テスト"},
+		"ドロップダウン": {"type": "DROP_DOWN", "value": "A"},
+		"数値": {"type": "NUMBER", "value": "1.5"},
+		"計算": {"type": "CALC", "value": "3"},
+		"日付": {"type": "DATE", "value": "2020-01-02"},
+		"日時": {"type": "DATETIME", "value": "2020-01-02T03:04:00Z"},
+		"チェックボックス": {"type": "CHECK_BOX", "value": ["a", "b"]},
+		"作成者": {"type": "CREATOR", "value": {"code": "sato", "name": "佐藤"}},
+		"テーブル": {"type": "SUBTABLE", "value": [{"id": "1", "value": {}}]}
+	}`)
+
+	var r Record
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	fs := r.Fields
+
+	if v, err := fs.String("文字列"); err != nil || v != "テスト" {
+		t.Errorf("unexpected 文字列: %s, %v", v, err)
+	}
+	if v, err := fs.String("ドロップダウン"); err != nil || v != "A" {
+		t.Errorf("unexpected ドロップダウン: %s, %v", v, err)
+	}
+	if v, err := fs.Number("数値"); err != nil || v.String() != "1.5" {
+		t.Errorf("unexpected 数値: %s, %v", v, err)
+	}
+	if v, err := fs.Number("計算"); err != nil || v.String() != "3" {
+		t.Errorf("unexpected 計算: %s, %v", v, err)
+	}
+	if v, err := fs.Date("日付"); err != nil || v != NewDate(2020, time.January, 2) {
+		t.Errorf("unexpected 日付: %s, %v", v, err)
+	}
+	if v, err := fs.Time("日時"); err != nil || !v.Equal(time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC)) {
+		t.Errorf("unexpected 日時: %s, %v", v, err)
+	}
+	if v, err := fs.Strings("チェックボックス"); err != nil || !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("unexpected チェックボックス: %s, %v", v, err)
+	}
+	if v, err := fs.Users("作成者"); err != nil || len(v) != 1 || v[0].Code != "sato" {
+		t.Errorf("unexpected 作成者: %v, %v", v, err)
+	}
+	if v, err := fs.Table("テーブル"); err != nil || len(v) != 1 || v[0].ID != "1" {
+		t.Errorf("unexpected テーブル: %v, %v", v, err)
+	}
+
+	// 型が合わない場合はコードとタイプを含むエラーを返す
+	_, err = fs.Number("文字列")
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Code != "文字列" || fe.Type != FieldTypeSingleLineText || fe.Want != "number" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	_, err = fs.String("存在しない")
+	if !errors.As(err, &fe) || fe.Type != "" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFieldsSetter(t *testing.T) {
+	fs := Fields{
+		"ラジオ":  RadioButtonField("A"),
+		"複数選択": MultiSelectField{},
+		"作成者":  &UserField{Code: "suzuki"},
+	}
+
+	tests := []struct {
+		set      func() error
+		code     string
+		expected Field
+	}{
+		{func() error { return fs.SetString("文字列", "a") }, "文字列", SingleLineTextField("a")},
+		{func() error { return fs.SetString("ラジオ", "B") }, "ラジオ", RadioButtonField("B")},
+		{func() error { return fs.SetNumber("数値", MustParseDecimal("1.5")) }, "数値", DecimalField{MustParseDecimal("1.5")}},
+		{func() error { return fs.SetDate("日付", NewDate(2020, 1, 2)) }, "日付", DateField{NewDate(2020, 1, 2)}},
+		{func() error { return fs.SetStrings("複数選択", []string{"x"}) }, "複数選択", MultiSelectField{"x"}},
+		{func() error { return fs.SetUsers("ユーザー", "sato") }, "ユーザー", []*UserField{{Code: "sato"}}},
+		{func() error { return fs.SetNull("ラジオ") }, "ラジオ", RadioButtonField("")},
+		{func() error { return fs.SetUsers("作成者", "sato") }, "作成者", &UserField{Code: "sato", fieldType: FieldTypeCreator}},
+	}
+
+	for _, test := range tests {
+		if err := test.set(); err != nil {
+			t.Error(err)
+			continue
+		}
+		if !reflect.DeepEqual(test.expected, fs[test.code]) {
+			t.Errorf("expected: %#v, actual: %#v", test.expected, fs[test.code])
+		}
+	}
+
+	// 既存のフィールドと型が合わない場合
+	err := fs.SetStrings("文字列", []string{"a"})
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Code != "文字列" || fe.Type != FieldTypeSingleLineText {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// 既存のフィールドの型が未対応の場合は not found にしない
+	fs["独自"] = struct{}{}
+	err = fs.SetString("独自", "a")
+	if !errors.As(err, &fe) || fe.Type == "" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFieldsSetWithForm(t *testing.T) {
+	form := FormFields{
+		"ドロップダウン": {Code: "ドロップダウン", Type: FieldTypeSingleSelect},
+		"数値":      {Code: "数値", Type: FieldTypeNumber},
+		"時刻":      {Code: "時刻", Type: FieldTypeTime},
+	}
+
+	fs := Fields{}
+	if err := fs.SetWithForm(form, "ドロップダウン", "A"); err != nil {
+		t.Error(err)
+	}
+	if err := fs.SetWithForm(form, "数値", 3); err != nil {
+		t.Error(err)
+	}
+	if err := fs.SetWithForm(form, "時刻", "09:30"); err != nil {
+		t.Error(err)
+	}
+
+	expected := Fields{
+		"ドロップダウン": SingleSelectField{"A"},
+		"数値":      NumberField(3),
+		"時刻":      TimeField("09:30"),
+	}
+	if !reflect.DeepEqual(expected, fs) {
+		t.Errorf("expected: %#v, actual: %#v", expected, fs)
+	}
+
+	if err := fs.SetWithForm(form, "数値", "abc"); err == nil {
+		t.Error("expected error")
+	}
+	if err := fs.SetWithForm(form, "時刻", "25:00"); err == nil {
+		t.Error("expected error")
+	}
+	if err := fs.SetWithForm(form, "存在しない", "a"); err == nil {
+		t.Error("expected error")
+	}
+}
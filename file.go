@@ -0,0 +1,199 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ProgressFunc はアップロードした合計のバイト数を受け取る
+type ProgressFunc func(written int64)
+
+// UploadFile はファイルをアップロードし、ファイルキーを返す
+// ファイルキーをファイルフィールドに指定してレコードを登録・更新するまでファイルは添付されない
+func (repo *Repository) UploadFile(ctx context.Context, name, contentType string, r io.Reader) (string, error) {
+	return repo.UploadFileWithProgress(ctx, name, contentType, r, nil)
+}
+
+// UploadFileWithProgress は UploadFile と同じだが、読み込むたびに progress を呼ぶ
+func (repo *Repository) UploadFileWithProgress(ctx context.Context, name, contentType string, r io.Reader, progress ProgressFunc) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if contentType == "" {
+		contentType = detectContentType(name)
+	}
+	if progress != nil {
+		r = &progressReader{r: r, progress: progress}
+	}
+
+	data, err := repo.Client.upload(ctx, name, contentType, r)
+	if err != nil {
+		return "", errors.Wrapf(err, "upload %s failed", name)
+	}
+
+	raw := struct {
+		FileKey string `json:"fileKey"`
+	}{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return "", err
+	}
+	return raw.FileKey, nil
+}
+
+// DownloadFile はファイルキーのファイルを w に書き込む
+func (repo *Repository) DownloadFile(ctx context.Context, fileKey string, w io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return repo.Client.download(ctx, fileKey, w)
+}
+
+// AttachFiles はローカルのファイルをアップロードし、レコードのファイルフィールド code に追加する
+// 既に添付されているファイルは残す。AddRecord、UpdateRecord でレコードを保存するとファイルが添付される
+func (repo *Repository) AttachFiles(ctx context.Context, r *Record, code string, paths ...string) error {
+	f, ok := r.Fields[code]
+	if !ok || f == nil {
+		f = FileField{}
+	}
+	files, ok := f.(FileField)
+	if !ok {
+		return errors.Errorf("field %s is not a file field", code)
+	}
+
+	// 元のスライスを書き換えない
+	files = append(FileField{}, files...)
+	for _, path := range paths {
+		file, err := repo.uploadLocalFile(ctx, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+
+	if r.Fields == nil {
+		r.Fields = Fields{}
+	}
+	r.Fields[code] = files
+	return nil
+}
+
+func (repo *Repository) uploadLocalFile(ctx context.Context, path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(path)
+	contentType := detectContentType(name)
+	key, err := repo.UploadFile(ctx, name, contentType, f)
+	if err != nil {
+		return nil, err
+	}
+	return &File{ContentType: contentType, FileKey: key, Name: name, Size: uint64(info.Size())}, nil
+}
+
+// detectContentType はファイル名の拡張子から Content-Type を返す
+func detectContentType(name string) string {
+	if t := mime.TypeByExtension(filepath.Ext(name)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+type progressReader struct {
+	r        io.Reader
+	written  int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.written += int64(n)
+		r.progress(r.written)
+	}
+	return n, err
+}
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// upload は multipart/form-data でファイルを送る
+// ファイル全体をメモリに読み込まないように io.Pipe で書き込みながら送信する
+func (c *client) upload(ctx context.Context, name, contentType string, r io.Reader) ([]byte, error) {
+	u, err := newURL(c.endpointBase, APIEndpointFile, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", `form-data; name="file"; filename="`+quoteEscaper.Replace(name)+`"`)
+		h.Set("Content-Type", contentType)
+
+		part, err := mw.CreatePart(h)
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequest("POST", u, pr)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	data, err := c.do(req)
+	// 送信に失敗した場合に書き込み側を止める
+	pr.Close()
+	return data, err
+}
+
+func (c *client) download(ctx context.Context, fileKey string, w io.Writer) error {
+	u, err := newURL(c.endpointBase, APIEndpointFile, nil)
+	if err != nil {
+		return err
+	}
+	u += "?" + url.Values{"fileKey": {fileKey}}.Encode()
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	res, err := c.send(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	_, err = io.Copy(w, res.Body)
+	return err
+}
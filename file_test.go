@@ -0,0 +1,133 @@
+package kintone
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"fileKey": "key1"}`), nil
+	})
+
+	var written []int64
+	key, err := repo.UploadFileWithProgress(nil, "見積書.pdf", "", strings.NewReader("hello"), func(n int64) {
+		written = append(written, n)
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if key != "key1" {
+		t.Errorf("expected: key1, actual: %s", key)
+	}
+	req := c.requests[0]
+	if req.FileName != "見積書.pdf" || req.ContentType != "application/pdf" || string(req.Body) != "hello" {
+		t.Errorf("unexpected request: %#v", req)
+	}
+	if len(written) == 0 || written[len(written)-1] != 5 {
+		t.Errorf("unexpected progress: %v", written)
+	}
+}
+
+func TestAttachFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kintone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "memo.txt")
+	if err := ioutil.WriteFile(path, []byte("memo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	repo, _ := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		n++
+		return []byte(fmt.Sprintf(`{"fileKey": "key%d"}`, n)), nil
+	})
+
+	old := FileField{{FileKey: "old", Name: "old.txt"}}
+	r := &Record{Fields: Fields{"files": old}}
+	if err := repo.AttachFiles(nil, r, "files", path); err != nil {
+		t.Error(err)
+		return
+	}
+
+	files := r.Fields["files"].(FileField)
+	if len(files) != 2 || files[0].FileKey != "old" || files[1].FileKey != "key1" || files[1].Name != "memo.txt" || files[1].Size != 4 {
+		t.Errorf("unexpected files: %v", files)
+	}
+	if len(old) != 1 {
+		t.Error("original field was modified")
+	}
+
+	r.Fields["title"] = SingleLineTextField("")
+	if err := repo.AttachFiles(nil, r, "title", path); err == nil {
+		t.Error("expected error for non-file field")
+	}
+}
+
+func TestClientUploadDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != APIEndpointFile {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case "POST":
+			f, h, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"code": "CB_IJ01", "message": %q}`, err.Error())
+				return
+			}
+			defer f.Close()
+			data, _ := ioutil.ReadAll(f)
+			fmt.Fprintf(w, `{"fileKey": %q}`, h.Filename+":"+h.Header.Get("Content-Type")+":"+string(data))
+		case "GET":
+			if r.URL.Query().Get("fileKey") != "key1" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"code": "GAIA_BL01", "message": "not found"}`)
+				return
+			}
+			fmt.Fprint(w, "content")
+		}
+	}))
+	defer server.Close()
+
+	c := newClient("example", "user", "password", nil)
+	c.endpointBase, _ = url.Parse(server.URL)
+	repo := &Repository{Client: c, Token: make(chan struct{}, 1)}
+
+	key, err := repo.UploadFile(nil, `a"b.txt`, "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if key != `a"b.txt:text/plain:hello` {
+		t.Errorf("unexpected file key: %s", key)
+	}
+
+	var buf bytes.Buffer
+	if err := repo.DownloadFile(nil, "key1", &buf); err != nil {
+		t.Error(err)
+		return
+	}
+	if buf.String() != "content" {
+		t.Errorf("unexpected content: %s", buf.String())
+	}
+
+	if err := repo.DownloadFile(nil, "missing", &buf); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
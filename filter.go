@@ -0,0 +1,574 @@
+package kintone
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnsupportedFilter はレコードだけでは評価できない条件
+// TODAY()、LOGINUSER() などの関数は実行するユーザーや日時で結果が変わるため評価しない
+var ErrUnsupportedFilter = errors.New("unsupported filter")
+
+// Filter はプロセス管理のアクションの条件など、kintone のクエリ形式の絞り込み条件
+type Filter struct {
+	cond string
+	root filterNode
+}
+
+// ParseFilter は絞り込み条件を解析する。空の条件は全てのレコードに一致する
+// order by、limit、offset は指定できない
+func ParseFilter(cond string) (*Filter, error) {
+	f := &Filter{cond: cond}
+	if strings.TrimSpace(cond) == "" {
+		return f, nil
+	}
+
+	tokens, err := tokenizeFilter(cond)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	f.root, err = p.parseOr()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid filter %q", cond)
+	}
+	if t := p.peek(); t != nil {
+		return nil, fmt.Errorf("invalid filter %q: unexpected %s", cond, t.text)
+	}
+	return f, nil
+}
+
+func (f *Filter) String() string {
+	return f.cond
+}
+
+// Match はレコードが条件に一致するかどうかを返す
+// 条件のフィールドがレコードに無い場合や、評価できない条件の場合はエラーを返す
+func (f *Filter) Match(r *Record) (bool, error) {
+	if f.root == nil {
+		return true, nil
+	}
+	return f.root.match(r)
+}
+
+const (
+	tokenWord   = iota // フィールドコード、演算子のキーワード、関数名、引用符の無い値
+	tokenString        // "..."
+	tokenOp            // =, !=, <, >, <=, >=
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type filterToken struct {
+	kind int
+	text string
+}
+
+func tokenizeFilter(s string) ([]*filterToken, error) {
+	var tokens []*filterToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, &filterToken{tokenLParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, &filterToken{tokenRParen, ")"})
+			i++
+		case c == ',':
+			tokens = append(tokens, &filterToken{tokenComma, ","})
+			i++
+		case c == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(rs) && rs[i] != '"'; i++ {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+				}
+				b.WriteRune(rs[i])
+			}
+			if i == len(rs) {
+				return nil, fmt.Errorf("invalid filter %q: unterminated string", s)
+			}
+			tokens = append(tokens, &filterToken{tokenString, b.String()})
+			i++
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(rs) && rs[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("invalid filter %q: unexpected !", s)
+			}
+			tokens = append(tokens, &filterToken{tokenOp, op})
+			i += len(op)
+		default:
+			start := i
+			for i < len(rs) && !unicode.IsSpace(rs[i]) && !strings.ContainsRune(`()",=!<>`, rs[i]) {
+				i++
+			}
+			tokens = append(tokens, &filterToken{tokenWord, string(rs[start:i])})
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []*filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() *filterToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *filterParser) next() *filterToken {
+	t := p.peek()
+	if t != nil {
+		p.pos++
+	}
+	return t
+}
+
+// keyword は次のトークンがキーワード kw の場合に読み進める
+func (p *filterParser) keyword(kw string) bool {
+	t := p.peek()
+	if t != nil && t.kind == tokenWord && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) expect(kind int, text string) error {
+	t := p.next()
+	if t == nil {
+		return fmt.Errorf("expected %s", text)
+	}
+	if t.kind != kind {
+		return fmt.Errorf("expected %s, got %s", text, t.text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &filterAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseFactor() (filterNode, error) {
+	if t := p.peek(); t != nil && t.kind == tokenLParen {
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(tokenRParen, ")")
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	t := p.next()
+	if t == nil || t.kind != tokenWord {
+		return nil, errors.New("expected field code")
+	}
+	c := &filterComparison{code: t.text}
+
+	switch {
+	case p.keyword("in"):
+		c.op = "in"
+	case p.keyword("like"):
+		c.op = "like"
+	case p.keyword("not"):
+		switch {
+		case p.keyword("in"):
+			c.op = "not in"
+		case p.keyword("like"):
+			c.op = "not like"
+		default:
+			return nil, fmt.Errorf("expected in or like after %s not", c.code)
+		}
+	case p.keyword("is"):
+		c.op = "is empty"
+		if p.keyword("not") {
+			c.op = "is not empty"
+		}
+		if !p.keyword("empty") {
+			return nil, fmt.Errorf("expected empty after %s is", c.code)
+		}
+		return c, nil
+	default:
+		op := p.next()
+		if op == nil || op.kind != tokenOp {
+			return nil, fmt.Errorf("expected operator after %s", c.code)
+		}
+		c.op = op.text
+	}
+
+	if c.op == "in" || c.op == "not in" {
+		if err := p.expect(tokenLParen, "("); err != nil {
+			return nil, err
+		}
+		for {
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			c.values = append(c.values, v)
+			if t := p.peek(); t != nil && t.kind == tokenComma {
+				p.next()
+				continue
+			}
+			break
+		}
+		return c, p.expect(tokenRParen, ")")
+	}
+
+	v, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	c.values = []*filterValue{v}
+	return c, nil
+}
+
+func (p *filterParser) parseValue() (*filterValue, error) {
+	t := p.next()
+	if t == nil {
+		return nil, errors.New("expected value")
+	}
+	switch t.kind {
+	case tokenString:
+		return &filterValue{s: t.text}, nil
+	case tokenWord:
+		// 関数
+		if n := p.peek(); n != nil && n.kind == tokenLParen {
+			p.next()
+			for depth := 1; depth > 0; {
+				a := p.next()
+				if a == nil {
+					return nil, fmt.Errorf("unterminated function %s", t.text)
+				}
+				switch a.kind {
+				case tokenLParen:
+					depth++
+				case tokenRParen:
+					depth--
+				}
+			}
+			return &filterValue{fn: t.text}, nil
+		}
+		return &filterValue{s: t.text}, nil
+	}
+	return nil, fmt.Errorf("unexpected %s", t.text)
+}
+
+type filterNode interface {
+	match(r *Record) (bool, error)
+}
+
+type filterAnd struct {
+	left, right filterNode
+}
+
+func (n *filterAnd) match(r *Record) (bool, error) {
+	ok, err := n.left.match(r)
+	if err != nil || !ok {
+		return false, err
+	}
+	return n.right.match(r)
+}
+
+type filterOr struct {
+	left, right filterNode
+}
+
+func (n *filterOr) match(r *Record) (bool, error) {
+	ok, err := n.left.match(r)
+	if err != nil || ok {
+		return ok, err
+	}
+	return n.right.match(r)
+}
+
+type filterValue struct {
+	s  string
+	fn string // 関数名。関数は評価しない
+}
+
+type filterComparison struct {
+	code   string
+	op     string
+	values []*filterValue
+}
+
+func (c *filterComparison) String() string {
+	return fmt.Sprintf("%s %s", c.code, c.op)
+}
+
+func (c *filterComparison) match(r *Record) (bool, error) {
+	for _, v := range c.values {
+		if v.fn != "" {
+			return false, errors.Wrapf(ErrUnsupportedFilter, "%s()", v.fn)
+		}
+	}
+
+	f, ok := r.Fields[c.code]
+	if !ok && c.code == fieldCodeID && r.ID != "" {
+		f, ok = IDField(r.ID), true
+	}
+	if !ok {
+		return false, fmt.Errorf("field %s is not in the record", c.code)
+	}
+
+	switch c.op {
+	case "is empty":
+		return isEmptyField(f), nil
+	case "is not empty":
+		return !isEmptyField(f), nil
+	}
+
+	// 空の値は否定の条件にのみ一致する
+	if isEmptyField(f) {
+		return strings.HasPrefix(c.op, "not ") || c.op == "!=", nil
+	}
+
+	fs := Fields{c.code: f}
+
+	// 複数の値を持つフィールドは in、not in のみ使える
+	if values, ok := multiValues(fs, c.code); ok {
+		switch c.op {
+		case "in", "not in":
+			var found bool
+			for _, s := range values {
+				if c.contains(s) {
+					found = true
+					break
+				}
+			}
+			return found == (c.op == "in"), nil
+		case "like", "not like":
+			if _, ok := f.(FileField); ok {
+				var found bool
+				for _, s := range values {
+					if like(s, c.values[0].s) {
+						found = true
+						break
+					}
+				}
+				return found == (c.op == "like"), nil
+			}
+		}
+		return false, errors.Wrapf(ErrUnsupportedFilter, "%s on %s", c.op, FieldTypeOf(f))
+	}
+
+	cmp, err := c.compareFunc(fs, f)
+	if err != nil {
+		return false, err
+	}
+
+	switch c.op {
+	case "in", "not in":
+		var found bool
+		for _, v := range c.values {
+			n, err := cmp(v.s)
+			if err != nil {
+				return false, err
+			}
+			if n == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (c.op == "in"), nil
+	case "like", "not like":
+		s, err := fs.String(c.code)
+		if err != nil {
+			return false, errors.Wrapf(ErrUnsupportedFilter, "%s on %s", c.op, FieldTypeOf(f))
+		}
+		return like(s, c.values[0].s) == (c.op == "like"), nil
+	}
+
+	n, err := cmp(c.values[0].s)
+	if err != nil {
+		return false, err
+	}
+	switch c.op {
+	case "=":
+		return n == 0, nil
+	case "!=":
+		return n != 0, nil
+	case "<":
+		return n < 0, nil
+	case "<=":
+		return n <= 0, nil
+	case ">":
+		return n > 0, nil
+	case ">=":
+		return n >= 0, nil
+	}
+	return false, fmt.Errorf("unknown operator %s", c.op)
+}
+
+func (c *filterComparison) contains(s string) bool {
+	for _, v := range c.values {
+		if v.s == s {
+			return true
+		}
+	}
+	return false
+}
+
+// compareFunc はフィールドの値と条件の値を比較する関数を返す
+func (c *filterComparison) compareFunc(fs Fields, f Field) (func(v string) (int, error), error) {
+	if d, err := fs.Number(c.code); err == nil {
+		return func(v string) (int, error) {
+			x, err := ParseDecimal(v)
+			if err != nil {
+				return 0, fmt.Errorf("field %s: invalid number %q", c.code, v)
+			}
+			return d.Cmp(x), nil
+		}, nil
+	}
+
+	if d, err := fs.Date(c.code); err == nil {
+		return func(v string) (int, error) {
+			x, err := ParseDate(v)
+			if err != nil {
+				return 0, fmt.Errorf("field %s: invalid date %q", c.code, v)
+			}
+			return compareStrings(d.String(), x.String()), nil
+		}, nil
+	}
+
+	if t, err := fs.Time(c.code); err == nil {
+		return func(v string) (int, error) {
+			// 日付のみの場合はフィールドのタイムゾーンの日付で比較する
+			if x, err := ParseDate(v); err == nil {
+				return compareStrings(DateOf(t).String(), x.String()), nil
+			}
+			for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05Z0700"} {
+				if x, err := time.Parse(layout, v); err == nil {
+					switch {
+					case t.Before(x):
+						return -1, nil
+					case t.After(x):
+						return 1, nil
+					}
+					return 0, nil
+				}
+			}
+			return 0, fmt.Errorf("field %s: invalid datetime %q", c.code, v)
+		}, nil
+	}
+
+	if s, err := fs.String(c.code); err == nil {
+		// 文字列の大小は時刻フィールドのみ比較できる
+		if _, ok := f.(TimeField); !ok && strings.ContainsAny(c.op, "<>") {
+			return nil, errors.Wrapf(ErrUnsupportedFilter, "%s on %s", c.op, FieldTypeOf(f))
+		}
+		return func(v string) (int, error) {
+			return compareStrings(s, v), nil
+		}, nil
+	}
+
+	return nil, errors.Wrapf(ErrUnsupportedFilter, "%s on %s", c.op, FieldTypeOf(f))
+}
+
+// multiValues は複数の値を持つフィールドの値を返す
+func multiValues(fs Fields, code string) ([]string, bool) {
+	if ss, err := fs.Strings(code); err == nil {
+		return ss, true
+	}
+	if us, err := fs.Users(code); err == nil {
+		return userCodes(us), true
+	}
+	if os, err := fs.Organizations(code); err == nil {
+		codes := make([]string, len(os))
+		for i, o := range os {
+			codes[i] = o.Code
+		}
+		return codes, true
+	}
+	if gs, err := fs.Groups(code); err == nil {
+		codes := make([]string, len(gs))
+		for i, g := range gs {
+			codes[i] = g.Code
+		}
+		return codes, true
+	}
+	if files, err := fs.Files(code); err == nil {
+		names := make([]string, len(files))
+		for i, f := range files {
+			names[i] = f.Name
+		}
+		return names, true
+	}
+	return nil, false
+}
+
+func isEmptyField(f Field) bool {
+	if IsNull(f) {
+		return true
+	}
+	if s, err := (Fields{"": f}).String(""); err == nil {
+		return s == ""
+	}
+	if values, ok := multiValues(Fields{"": f}, ""); ok {
+		return len(values) == 0
+	}
+	return false
+}
+
+// like は大文字・小文字を区別せずに部分一致するかどうかを返す
+func like(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func compareStrings(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package kintone
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestFilterMatch(t *testing.T) {
+	r := &Record{
+		ID: "5",
+		Fields: Fields{
+			"title":    SingleLineTextField("見積書の確認"),
+			"amount":   NumberField(1200),
+			"category": CheckBoxField{"A", "B"},
+			"note":     MultiLineTextField(""),
+			"status":   StatusField("確認中"),
+		},
+	}
+
+	tests := []struct {
+		cond     string
+		expected bool
+	}{
+		{``, true},
+		{`amount > 1000`, true},
+		{`amount >= 1200 and amount < 1200`, false},
+		{`amount < 1000 or title like "確認"`, true},
+		{`(amount < 1000 or amount > 2000) and status = "確認中"`, false},
+		{`status in ("確認中", "完了")`, true},
+		{`status not in ("確認中")`, false},
+		{`category in ("B")`, true},
+		{`category not in ("A", "C")`, false},
+		{`note is empty`, true},
+		{`note != "x"`, true},
+		{`title is not empty and $id = 5`, true},
+	}
+	for _, test := range tests {
+		f, err := ParseFilter(test.cond)
+		if err != nil {
+			t.Errorf("%s: %v", test.cond, err)
+			continue
+		}
+		ok, err := f.Match(r)
+		if err != nil {
+			t.Errorf("%s: %v", test.cond, err)
+			continue
+		}
+		if ok != test.expected {
+			t.Errorf("%s: expected: %v, actual: %v", test.cond, test.expected, ok)
+		}
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	for _, cond := range []string{`amount >`, `status in ("a"`, `title = "a" order by title`} {
+		if _, err := ParseFilter(cond); err == nil {
+			t.Errorf("%s: expected error", cond)
+		}
+	}
+
+	r := &Record{Fields: Fields{"date": DateField{}}}
+	f, err := ParseFilter(`date = TODAY()`)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := f.Match(r); errors.Cause(err) != ErrUnsupportedFilter {
+		t.Errorf("expected ErrUnsupportedFilter, actual: %v", err)
+	}
+
+	f, _ = ParseFilter(`missing = "a"`)
+	if _, err := f.Match(r); err == nil {
+		t.Error("expected error for missing field")
+	}
+}
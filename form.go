@@ -27,17 +27,67 @@ type FormField struct {
 	ThumbnailSize  string         `json:"thumbnailSize"`
 	Protocol       string         `json:"protocol"`       // リンクの種類（WEB, CALL, MAIL）
 	Format         string         `json:"format"`         // 計算フィールドの表示形式（NUMBER, NUMBER_DIGIT, DATETIME, DATE, TIME, HOUR_MINUTE, DAY_HOUR_MINUTE）
-	DisplayScale   string         `json:"disaplayScale"`  // 小数点以下の表示桁数です。未設定の場合は空です。
+	DisplayScale   string         `json:"displayScale"`   // 小数点以下の表示桁数です。未設定の場合は空です。
 	Unit           string         `json:"unit"`           // 単位の記号
 	UnitPosition   string         `json:"unitPosition"`   // 位記号の表示位置（BEFORE or AFTER）
 	Entities       []*Entity      `json:"entities"`       // 選択肢のユーザー
-	ReferenceTable ReferenceTable `json:"referenceTalbe"` // 関連レコード一覧
+	ReferenceTable ReferenceTable `json:"referenceTable"` // 関連レコード一覧
 	Lookup         Lookup         `json:"lookup"`
 	OpenGroup      bool           `json:"openGroup"`
 	Fields         FormFields     `json:"fields"`
 	Enabled        bool           `json:"enabled"`
 }
 
+// MarshalJSON はフォームの変更 API に送信する形式で出力する
+// 未設定（ゼロ値）の文字列、選択肢、関連レコード一覧、ルックアップは出力しない
+func (f FormField) MarshalJSON() ([]byte, error) {
+	type formField FormField
+	raw := struct {
+		formField
+		MaxValue       string          `json:"maxValue,omitempty"`
+		MinValue       string          `json:"minValue,omitempty"`
+		MaxLength      string          `json:"maxLength,omitempty"`
+		MinLength      string          `json:"minLength,omitempty"`
+		DefaultValue   interface{}     `json:"defaultValue,omitempty"`
+		Options        Options         `json:"options,omitempty"`
+		Align          string          `json:"align,omitempty"`
+		ThumbnailSize  string          `json:"thumbnailSize,omitempty"`
+		Protocol       string          `json:"protocol,omitempty"`
+		Format         string          `json:"format,omitempty"`
+		DisplayScale   string          `json:"displayScale,omitempty"`
+		Unit           string          `json:"unit,omitempty"`
+		UnitPosition   string          `json:"unitPosition,omitempty"`
+		Entities       []*Entity       `json:"entities,omitempty"`
+		ReferenceTable *ReferenceTable `json:"referenceTable,omitempty"`
+		Lookup         *Lookup         `json:"lookup,omitempty"`
+		Fields         FormFields      `json:"fields,omitempty"`
+	}{
+		formField:     formField(f),
+		MaxValue:      f.MaxValue,
+		MinValue:      f.MinValue,
+		MaxLength:     f.MaxLength,
+		MinLength:     f.MinLength,
+		DefaultValue:  f.DefaultValue,
+		Options:       f.Options,
+		Align:         f.Align,
+		ThumbnailSize: f.ThumbnailSize,
+		Protocol:      f.Protocol,
+		Format:        f.Format,
+		DisplayScale:  f.DisplayScale,
+		Unit:          f.Unit,
+		UnitPosition:  f.UnitPosition,
+		Entities:      f.Entities,
+		Fields:        f.Fields,
+	}
+	if f.ReferenceTable.RelatedApp != (RelatedApp{}) {
+		raw.ReferenceTable = &f.ReferenceTable
+	}
+	if f.Lookup.RelatedApp != (RelatedApp{}) {
+		raw.Lookup = &f.Lookup
+	}
+	return json.Marshal(raw)
+}
+
 type Options []string
 
 func (os *Options) UnmarshalJSON(data []byte) error {
@@ -69,6 +119,31 @@ func (os *Options) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON は選択肢を {"ラベル": {"label": "ラベル", "index": "0"}} の形式で出力する
+func (os Options) MarshalJSON() ([]byte, error) {
+	type option struct {
+		Label string `json:"label"`
+		Index int    `json:"index,string"`
+	}
+
+	raw := make(map[string]option, len(os))
+	for i, label := range os {
+		if label == "" {
+			continue
+		}
+		raw[label] = option{label, i}
+	}
+	return json.Marshal(raw)
+}
+
+// Entity の種類
+const (
+	EntityTypeUser         = "USER"
+	EntityTypeOrganization = "ORGANIZATION"
+	EntityTypeGroup        = "GROUP"
+)
+
+// Entity はユーザー、組織、グループ
 type Entity struct {
 	Code string `json:"code"`
 	Type string `json:"type"`
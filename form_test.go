@@ -47,6 +47,46 @@ func TestFormFields(t *testing.T) {
 	t.Log(f)
 }
 
+func TestFormFieldMarshalJSON(t *testing.T) {
+	f := FormField{
+		Code:         "ランク",
+		Label:        "ランク",
+		Type:         FieldTypeSingleSelect,
+		Required:     true,
+		DefaultValue: "A",
+		Options:      Options{"A", "", "B"},
+	}
+
+	actual, err := json.Marshal(f)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var raw map[string]interface{}
+	json.Unmarshal(actual, &raw)
+	for _, key := range []string{"lookup", "referenceTable", "maxLength", "entities"} {
+		if _, ok := raw[key]; ok {
+			t.Errorf("%s should be omitted: %s", key, actual)
+		}
+	}
+
+	expected := []byte(`{"A": {"label": "A", "index": "0"}, "B": {"label": "B", "index": "2"}}`)
+	options, _ := json.Marshal(raw["options"])
+	if !jsonEqual(expected, options) {
+		t.Errorf("expected: %s, actual: %s", expected, options)
+	}
+
+	var f2 FormField
+	if err := json.Unmarshal(actual, &f2); err != nil {
+		t.Error(err)
+		return
+	}
+	if f2.Code != f.Code || !f2.Required || len(f2.Options) != 3 || f2.Options[2] != "B" {
+		t.Errorf("unexpected field: %#v", f2)
+	}
+}
+
 func TestFormLayouts(t *testing.T) {
 	data := []byte(`
 		{
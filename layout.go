@@ -0,0 +1,270 @@
+package kintone
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// レイアウトのタイプ
+const (
+	LayoutTypeRow      = "ROW"
+	LayoutTypeSubtable = "SUBTABLE"
+	LayoutTypeGroup    = "GROUP"
+)
+
+// フィールド以外のレイアウトの要素のタイプ
+const (
+	LayoutFieldTypeLabel  = "LABEL"
+	LayoutFieldTypeSpacer = "SPACER"
+	LayoutFieldTypeBorder = "HR"
+)
+
+// LayoutRow は行を返す
+func LayoutRow(fields ...*FormLayoutField) *FormLayout {
+	return &FormLayout{Type: LayoutTypeRow, Fields: fields}
+}
+
+// LayoutField はフィールドを返す
+// フィールドタイプは LayoutBuilder.Build でフォームから設定する
+func LayoutField(code string) *FormLayoutField {
+	return &FormLayoutField{Code: code}
+}
+
+// LayoutLabel はラベルを返す
+func LayoutLabel(label string) *FormLayoutField {
+	return &FormLayoutField{Type: LayoutFieldTypeLabel, LabelName: label}
+}
+
+// LayoutSpacer はスペースを返す
+func LayoutSpacer(elementID string) *FormLayoutField {
+	return &FormLayoutField{Type: LayoutFieldTypeSpacer, ElementID: elementID}
+}
+
+// LayoutBorder は罫線を返す
+func LayoutBorder() *FormLayoutField {
+	return &FormLayoutField{Type: LayoutFieldTypeBorder}
+}
+
+// WithSize はフィールドのサイズを設定する。空の値は設定しない
+// innerHeight は文字列（複数行）とリッチエディターのみ使用する
+func (f *FormLayoutField) WithSize(width, height, innerHeight string) *FormLayoutField {
+	f.Size.Width = width
+	f.Size.Height = height
+	f.Size.InnerHeight = innerHeight
+	return f
+}
+
+// LayoutBuilder はフォームのフィールドからレイアウトを組み立てる
+type LayoutBuilder struct {
+	form    FormFields
+	layouts FormLayouts
+}
+
+// NewLayoutBuilder は form のフィールドを配置する LayoutBuilder を返す
+func NewLayoutBuilder(form FormFields) *LayoutBuilder {
+	return &LayoutBuilder{form: form}
+}
+
+// Row は行を追加する
+func (b *LayoutBuilder) Row(fields ...*FormLayoutField) *LayoutBuilder {
+	b.layouts = append(b.layouts, LayoutRow(fields...))
+	return b
+}
+
+// Group はグループを追加する
+func (b *LayoutBuilder) Group(code string, rows ...*FormLayout) *LayoutBuilder {
+	b.layouts = append(b.layouts, &FormLayout{Type: LayoutTypeGroup, Code: code, Layouts: rows})
+	return b
+}
+
+// Subtable はテーブルを追加する
+func (b *LayoutBuilder) Subtable(code string, fields ...*FormLayoutField) *LayoutBuilder {
+	b.layouts = append(b.layouts, &FormLayout{Type: LayoutTypeSubtable, Code: code, Fields: fields})
+	return b
+}
+
+// Build はフィールドタイプを設定し、ValidateLayout で検証したレイアウトを返す
+func (b *LayoutBuilder) Build() (FormLayouts, error) {
+	var fill func(form FormFields, layouts []*FormLayout)
+	fill = func(form FormFields, layouts []*FormLayout) {
+		for _, l := range layouts {
+			fieldForm := form
+			if l.Type == LayoutTypeSubtable {
+				if ff, ok := form[l.Code]; ok && ff != nil {
+					fieldForm = ff.Fields
+				}
+			}
+			for _, f := range l.Fields {
+				if f.Type != "" {
+					continue
+				}
+				if ff, ok := fieldForm[f.Code]; ok && ff != nil {
+					f.Type = ff.Type
+				}
+			}
+			fill(form, l.Layouts)
+		}
+	}
+	fill(b.form, b.layouts)
+
+	if err := ValidateLayout(b.form, b.layouts); err != nil {
+		return nil, err
+	}
+	return b.layouts, nil
+}
+
+// LayoutError はレイアウトの検証結果
+type LayoutError struct {
+	Unplaced   []string // 配置されていないフィールド
+	Unknown    []string // フォームに存在しない、または配置できないフィールド
+	Duplicated []string // 複数回配置されたフィールド
+	Misplaced  []string // テーブル・グループの種類や配置場所が正しくないフィールド
+}
+
+func (e *LayoutError) Error() string {
+	var msgs []string
+	for _, v := range []struct {
+		name  string
+		codes []string
+	}{
+		{"unplaced", e.Unplaced},
+		{"unknown", e.Unknown},
+		{"duplicated", e.Duplicated},
+		{"misplaced", e.Misplaced},
+	} {
+		if len(v.codes) > 0 {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", v.name, strings.Join(v.codes, ", ")))
+		}
+	}
+	return "kintone: invalid layout: " + strings.Join(msgs, "; ")
+}
+
+// ValidateLayout はフォームの全てのフィールドがレイアウトに一度ずつ配置されているかを検証する
+// レコード番号、作成者などは配置しなくてもよい。ステータス、作業者、カテゴリーは配置できない
+// 検証エラーがある場合は *LayoutError を返す
+func ValidateLayout(form FormFields, layouts FormLayouts) error {
+	// フィールドコードと配置するテーブル（テーブル外は空）
+	tables := make(map[string]string)
+	for code, ff := range form {
+		if ff == nil || !isLayoutFieldType(ff.Type) {
+			continue
+		}
+		tables[code] = ""
+		if ff.Type == FieldTypeSubtable {
+			for c := range ff.Fields {
+				tables[c] = code
+			}
+		}
+	}
+
+	e := &LayoutError{}
+	placed := make(map[string]int)
+	place := func(code, table string) {
+		placed[code]++
+		if placed[code] == 2 {
+			e.Duplicated = append(e.Duplicated, code)
+		}
+		t, ok := tables[code]
+		if !ok {
+			e.Unknown = append(e.Unknown, code)
+		} else if t != table {
+			e.Misplaced = append(e.Misplaced, code)
+		}
+	}
+
+	var walk func(layouts []*FormLayout)
+	walk = func(layouts []*FormLayout) {
+		for _, l := range layouts {
+			var table string
+			switch l.Type {
+			case LayoutTypeSubtable, LayoutTypeGroup:
+				place(l.Code, "")
+				if ff, ok := form[l.Code]; ok && ff != nil && ff.Type != l.Type {
+					e.Misplaced = append(e.Misplaced, l.Code)
+				}
+				if l.Type == LayoutTypeSubtable {
+					table = l.Code
+				}
+			}
+			for _, code := range (FormLayouts{{Fields: l.Fields}}).Codes() {
+				if code != "" {
+					place(code, table)
+				}
+			}
+			walk(l.Layouts)
+		}
+	}
+	walk(layouts)
+
+	for code := range tables {
+		if placed[code] == 0 && (tables[code] != "" || !isSystemFieldType(form[code].Type)) {
+			e.Unplaced = append(e.Unplaced, code)
+		}
+	}
+
+	if len(e.Unplaced)+len(e.Unknown)+len(e.Duplicated)+len(e.Misplaced) == 0 {
+		return nil
+	}
+	sort.Strings(e.Unplaced)
+	sort.Strings(e.Unknown)
+	sort.Strings(e.Duplicated)
+	sort.Strings(e.Misplaced)
+	return e
+}
+
+// isLayoutFieldType はフィールドタイプをレイアウトに配置できるかどうかを返す
+func isLayoutFieldType(fieldType string) bool {
+	switch fieldType {
+	case FieldTypeStatus, FieldTypeAssignee, FieldTypeCategory:
+		return false
+	}
+	return true
+}
+
+// isSystemFieldType はレコード番号、作成者などアプリに必ずあるフィールドタイプかどうかを返す
+func isSystemFieldType(fieldType string) bool {
+	switch fieldType {
+	case FieldTypeRecordNumber, FieldTypeCreator, FieldTypeCreatedDateTime, FieldTypeModifier, FieldTypeUpdatedDateTime:
+		return true
+	}
+	return false
+}
+
+// ReadPreviewFormLayout は運用環境に反映する前のフォームのレイアウトと revision を返す
+func (repo *Repository) ReadPreviewFormLayout(appID int) (FormLayouts, string, error) {
+	data, err := repo.Client.get(APIEndpointPreviewFormLayout, &Query{AppID: appID})
+	if err != nil {
+		return nil, "", err
+	}
+	raw := struct {
+		Revision string      `json:"revision"`
+		Layout   FormLayouts `json:"layout"`
+	}{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw.Layout, raw.Revision, nil
+}
+
+// UpdateFormLayout はフォームのレイアウトを変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateFormLayout(appID int, layouts FormLayouts, revision string) (string, error) {
+	body, err := json.Marshal(struct {
+		App      int         `json:"app"`
+		Layout   FormLayouts `json:"layout"`
+		Revision string      `json:"revision,omitempty"`
+	}{appID, layouts, revision})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := repo.Client.put(APIEndpointPreviewFormLayout, body)
+	if err != nil {
+		return "", err
+	}
+	return unmarshalRevision(data)
+}
@@ -0,0 +1,92 @@
+package kintone
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var testLayoutForm = FormFields{
+	"名前":     {Code: "名前", Type: FieldTypeSingleLineText},
+	"メモ":     {Code: "メモ", Type: FieldTypeMultiLineText},
+	"詳細":     {Code: "詳細", Type: LayoutTypeGroup},
+	"レコード番号": {Code: "レコード番号", Type: FieldTypeRecordNumber},
+	"ステータス":  {Code: "ステータス", Type: FieldTypeStatus},
+	"明細": {Code: "明細", Type: FieldTypeSubtable, Fields: FormFields{
+		"商品": {Code: "商品", Type: FieldTypeSingleLineText},
+		"数量": {Code: "数量", Type: FieldTypeNumber},
+	}},
+}
+
+func TestLayoutBuilder(t *testing.T) {
+	layouts, err := NewLayoutBuilder(testLayoutForm).
+		Row(LayoutLabel("基本情報"), LayoutField("名前").WithSize("200", "", "")).
+		Group("詳細", LayoutRow(LayoutField("メモ").WithSize("400", "", "100"), LayoutSpacer("space")), LayoutRow(LayoutBorder())).
+		Subtable("明細", LayoutField("商品"), LayoutField("数量")).
+		Build()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if f := layouts[1].Layouts[0].Fields[0]; f.Type != FieldTypeMultiLineText || f.Size.InnerHeight != "100" {
+		t.Errorf("unexpected field: %#v", f)
+	}
+	if f := layouts[2].Fields[1]; f.Type != FieldTypeNumber {
+		t.Errorf("unexpected field: %#v", f)
+	}
+
+	expected := []string{"", "名前", "メモ", "", "", "商品", "数量"}
+	if actual := layouts.Codes(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestValidateLayout(t *testing.T) {
+	_, err := NewLayoutBuilder(testLayoutForm).
+		Row(LayoutField("名前"), LayoutField("名前"), LayoutField("商品"), LayoutField("ステータス"), LayoutField("存在しない")).
+		Subtable("明細", LayoutField("数量")).
+		Build()
+
+	var le *LayoutError
+	if !errors.As(err, &le) {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	expected := &LayoutError{
+		Unplaced:   []string{"メモ", "詳細"},
+		Unknown:    []string{"ステータス", "存在しない"},
+		Duplicated: []string{"名前"},
+		Misplaced:  []string{"商品"},
+	}
+	if !reflect.DeepEqual(expected, le) {
+		t.Errorf("expected: %v, actual: %v", expected, le)
+	}
+}
+
+func TestUpdateFormLayout(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"revision": "5"}`), nil
+	})
+
+	layouts := FormLayouts{LayoutRow(LayoutLabel("見出し"))}
+	revision, err := repo.UpdateFormLayout(1, layouts, "4")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if revision != "5" {
+		t.Errorf("expected: 5, actual: %s", revision)
+	}
+
+	expected := []byte(`{
+		"app": 1,
+		"revision": "4",
+		"layout": [{"type": "ROW", "fields": [{"type": "LABEL", "label": "見出し", "size": {}}]}]
+	}`)
+	req := c.requests[0]
+	if req.Method != "PUT" || req.Path != APIEndpointPreviewFormLayout || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+}
@@ -0,0 +1,672 @@
+package kintone
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 構造体タグによる Record との相互変換
+//
+//	type Customer struct {
+//		ID       string          `kintone:"$id"`
+//		Name     string          `kintone:"name"`
+//		Age      int             `kintone:"age"`
+//		Birthday *time.Time      `kintone:"birthday,date"`
+//		Tags     CheckBoxField   `kintone:"tags"`
+//		Members  []*UserField    `kintone:"members"`
+//		Orders   []*Order        `kintone:"orders"` // サブテーブル
+//	}
+//
+// タグのオプション
+//   - omitempty: ゼロ値の場合は Marshal で出力しない
+//   - date: time.Time を DATE として扱う（デフォルトは DATETIME）。Date 型は常に DATE として扱う
+//   - time: time.Time を TIME として扱う
+//   - readonly: Unmarshal のみ行い、Marshal では出力しない（計算フィールドなど）
+//
+// "$id" は Record.ID、"$revision" は読み取り専用として扱う。
+
+const tagName = "kintone"
+
+const (
+	fieldCodeID       = "$id"
+	fieldCodeRevision = "$revision"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	decimalType = reflect.TypeOf(Decimal{})
+	dateType    = reflect.TypeOf(Date{})
+)
+
+// Marshal は構造体を Record に変換する
+func Marshal(v interface{}) (*Record, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("kintone: Marshal(nil %s)", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("kintone: Marshal(non-struct %s)", rv.Type())
+	}
+
+	r := &Record{Fields: make(Fields)}
+	if err := marshalStruct(rv, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// MarshalRecords は構造体のスライスを Record のスライスに変換する
+func MarshalRecords(v interface{}) ([]*Record, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("kintone: MarshalRecords(non-slice %s)", rv.Type())
+	}
+
+	rs := make([]*Record, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		r, err := Marshal(rv.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		rs[i] = r
+	}
+	return rs, nil
+}
+
+// Unmarshal は Record の値を構造体に格納する
+func Unmarshal(r *Record, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("kintone: Unmarshal(non-pointer %T)", v)
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("kintone: Unmarshal(non-struct %s)", rv.Type())
+	}
+	if r == nil {
+		return nil
+	}
+	return unmarshalStruct(r, rv)
+}
+
+// UnmarshalRecords は Record のスライスを構造体のスライスに格納する
+// v は *[]T または *[]*T
+func UnmarshalRecords(rs []*Record, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("kintone: UnmarshalRecords(non-slice-pointer %T)", v)
+	}
+	rv = rv.Elem()
+
+	out := reflect.MakeSlice(rv.Type(), len(rs), len(rs))
+	for i, r := range rs {
+		if err := unmarshalRecordInto(r, out.Index(i)); err != nil {
+			return err
+		}
+	}
+	rv.Set(out)
+	return nil
+}
+
+// FieldCodes は構造体の kintone タグからフィールドコードの一覧を返す
+// Query.Fields に指定して取得するフィールドを絞り込む用途で使う
+func FieldCodes(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := structFields(t)
+	codes := make([]string, len(fields))
+	for i, f := range fields {
+		codes[i] = f.code
+	}
+	return codes
+}
+
+//+structField
+
+type structField struct {
+	code      string
+	index     []int
+	omitEmpty bool
+	readOnly  bool
+	date      bool
+	time      bool
+}
+
+// structFields は kintone タグの付いたフィールドを列挙する
+// 匿名フィールドはタグが無ければ展開する
+func structFields(t reflect.Type) []*structField {
+	var fields []*structField
+
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			tag, ok := sf.Tag.Lookup(tagName)
+
+			idx := make([]int, len(index)+1)
+			copy(idx, index)
+			idx[len(index)] = i
+
+			if !ok {
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous && ft.Kind() == reflect.Struct {
+					walk(ft, idx)
+				}
+				continue
+			}
+			if tag == "-" || sf.PkgPath != "" {
+				continue
+			}
+
+			opts := strings.Split(tag, ",")
+			f := &structField{code: opts[0], index: idx}
+			for _, o := range opts[1:] {
+				switch o {
+				case "omitempty":
+					f.omitEmpty = true
+				case "readonly":
+					f.readOnly = true
+				case "date":
+					f.date = true
+				case "time":
+					f.time = true
+				}
+			}
+			if f.code == "" {
+				f.code = sf.Name
+			}
+			fields = append(fields, f)
+		}
+	}
+	walk(t, nil)
+
+	return fields
+}
+
+// fieldByIndex は埋め込みポインタを必要に応じて初期化しながらフィールドを辿る
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+//-structField
+
+//+marshal
+
+func marshalStruct(rv reflect.Value, r *Record) error {
+	for _, sf := range structFields(rv.Type()) {
+		fv, ok := fieldByIndex(rv, sf.index, false)
+		if !ok {
+			continue
+		}
+
+		switch sf.code {
+		case fieldCodeID:
+			r.ID = marshalID(fv)
+			continue
+		case fieldCodeRevision:
+			continue
+		}
+
+		if sf.readOnly || (sf.omitEmpty && fv.IsZero()) {
+			continue
+		}
+
+		f, err := marshalField(fv, sf)
+		if err != nil {
+			return fmt.Errorf("kintone: field %s: %s", sf.code, err)
+		}
+		if f == nil {
+			continue
+		}
+		r.Fields[sf.code] = f
+	}
+	return nil
+}
+
+// marshalNullField は型 t に対応する空のフィールドを返す
+func marshalNullField(t reflect.Type, sf *structField) (Field, error) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		// **T や *UserField などは出力しない
+		return nil, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return NullNumberField{}, nil
+	}
+	f, err := marshalField(reflect.Zero(t), sf)
+	if err != nil || !IsNull(f) {
+		return nil, err
+	}
+	return f, nil
+}
+
+func marshalID(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() == 0 {
+			return ""
+		}
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() == 0 {
+			return ""
+		}
+		return strconv.FormatUint(v.Uint(), 10)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// marshalField は構造体フィールドの値を Field に変換する
+// nil ポインタの場合は空の値を返し、更新時にフィールドの値を空にする
+// フィールドを出力しない場合は omitempty を指定する
+func marshalField(v reflect.Value, sf *structField) (Field, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return marshalNullField(v.Type().Elem(), sf)
+		}
+		// *UserField などはそのまま使う
+		if isFieldType(v.Type()) {
+			return v.Interface(), nil
+		}
+		v = v.Elem()
+	}
+
+	if isFieldType(v.Type()) {
+		return v.Interface(), nil
+	}
+
+	if v.Type() == decimalType {
+		return DecimalField{v.Interface().(Decimal)}, nil
+	}
+
+	if v.Type() == dateType {
+		return DateField{v.Interface().(Date)}, nil
+	}
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		switch {
+		case t.IsZero() && sf.date:
+			return DateField{}, nil
+		case t.IsZero() && sf.time:
+			return TimeField(""), nil
+		case sf.date:
+			return DateField{DateOf(t)}, nil
+		case sf.time:
+			return TimeField(t.Format("15:04")), nil
+		default:
+			return DateTimeField{Value: t}, nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return SingleLineTextField(v.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NumberField(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return NumberField(int64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return DecimalField{NewDecimalFromFloat(v.Float())}, nil
+	case reflect.Slice:
+		et := v.Type().Elem()
+		if et.Kind() == reflect.String {
+			out := make(CheckBoxField, v.Len())
+			for i := range out {
+				out[i] = v.Index(i).String()
+			}
+			return out, nil
+		}
+		if isStructType(et) {
+			return marshalTable(v)
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func marshalTable(v reflect.Value) (TableField, error) {
+	rows := make(TableField, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		rv := v.Index(i)
+		if rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				continue
+			}
+			rv = rv.Elem()
+		}
+		r := &Record{Fields: make(Fields)}
+		if err := marshalStruct(rv, r); err != nil {
+			return nil, err
+		}
+		rows = append(rows, r)
+	}
+	return rows, nil
+}
+
+//-marshal
+
+//+unmarshal
+
+func unmarshalRecordInto(r *Record, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if r == nil {
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("kintone: cannot unmarshal record into %s", v.Type())
+	}
+	if r == nil {
+		return nil
+	}
+	return unmarshalStruct(r, v)
+}
+
+func unmarshalStruct(r *Record, rv reflect.Value) error {
+	for _, sf := range structFields(rv.Type()) {
+		var f Field
+		switch sf.code {
+		case fieldCodeID:
+			f = IDField(r.ID)
+			if v, ok := r.Fields[fieldCodeID]; ok && r.ID == "" {
+				f = v
+			}
+		default:
+			v, ok := r.Fields[sf.code]
+			if !ok {
+				continue
+			}
+			f = v
+		}
+
+		fv, _ := fieldByIndex(rv, sf.index, true)
+		if err := unmarshalField(f, fv, sf); err != nil {
+			return fmt.Errorf("kintone: field %s: %s", sf.code, err)
+		}
+	}
+	return nil
+}
+
+// unmarshalField は Field の値を構造体フィールドに格納する
+func unmarshalField(f Field, v reflect.Value, sf *structField) error {
+	if f == nil {
+		return nil
+	}
+	fv := reflect.ValueOf(f)
+
+	if fv.Type().AssignableTo(v.Type()) {
+		v.Set(fv)
+		return nil
+	}
+
+	// *UserField -> UserField
+	if fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Elem().Type().AssignableTo(v.Type()) {
+		v.Set(fv.Elem())
+		return nil
+	}
+
+	// 名前付きの型への変換
+	if fv.Kind() == reflect.Ptr && !fv.IsNil() && v.Kind() == reflect.Struct && fv.Elem().Type().ConvertibleTo(v.Type()) {
+		v.Set(fv.Elem().Convert(v.Type()))
+		return nil
+	}
+
+	if v.Kind() == reflect.Ptr {
+		if IsNull(f) {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalField(f, v.Elem(), sf)
+	}
+
+	if v.Type() == decimalType {
+		d, err := fieldDecimal(f)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(d))
+		return nil
+	}
+
+	if v.Type() == dateType {
+		switch f := f.(type) {
+		case DateField:
+			v.Set(reflect.ValueOf(f.Value))
+			return nil
+		case DateTimeField:
+			v.Set(reflect.ValueOf(f.Date(nil)))
+			return nil
+		}
+		return fmt.Errorf("cannot unmarshal %T into %s", f, v.Type())
+	}
+
+	if v.Type() == timeType {
+		switch f := f.(type) {
+		case DateField:
+			if f.IsNull() {
+				v.Set(reflect.Zero(v.Type()))
+				return nil
+			}
+			t, err := f.Time()
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		case DateTimeField:
+			v.Set(reflect.ValueOf(f.Value))
+			return nil
+		case TimeField:
+			if f == "" {
+				return nil
+			}
+			t, err := time.Parse("15:04", string(f))
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+		return fmt.Errorf("cannot unmarshal %T into %s", f, v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		switch f := f.(type) {
+		case SingleSelectField:
+			v.SetString(f.String())
+			return nil
+		}
+		if fv.Kind() == reflect.String {
+			v.SetString(fv.String())
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := f.(NullNumberField); ok {
+			v.SetInt(0)
+			return nil
+		}
+		if f, ok := f.(DecimalField); ok {
+			i, err := f.Int64()
+			if err != nil {
+				return err
+			}
+			return setInt(v, i)
+		}
+		switch fv.Kind() {
+		case reflect.Int64:
+			return setInt(v, fv.Int())
+		case reflect.String:
+			if fv.String() == "" {
+				return nil
+			}
+			i, err := strconv.ParseInt(fv.String(), 10, 64)
+			if err != nil {
+				return err
+			}
+			return setInt(v, i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := f.(NullNumberField); ok {
+			v.SetUint(0)
+			return nil
+		}
+		if f, ok := f.(DecimalField); ok {
+			i, err := f.Int64()
+			if err != nil {
+				return err
+			}
+			return setUint(v, i)
+		}
+		switch fv.Kind() {
+		case reflect.Int64:
+			return setUint(v, fv.Int())
+		case reflect.String:
+			if fv.String() == "" {
+				return nil
+			}
+			i, err := strconv.ParseUint(fv.String(), 10, 64)
+			if err != nil {
+				return err
+			}
+			if v.OverflowUint(i) {
+				return fmt.Errorf("value %d overflows %s", i, v.Type())
+			}
+			v.SetUint(i)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		d, err := fieldDecimal(f)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(d.Float64())
+		return nil
+	case reflect.Slice:
+		et := v.Type().Elem()
+		if et.Kind() == reflect.String && fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String {
+			out := reflect.MakeSlice(v.Type(), fv.Len(), fv.Len())
+			for i := 0; i < fv.Len(); i++ {
+				out.Index(i).SetString(fv.Index(i).String())
+			}
+			v.Set(out)
+			return nil
+		}
+		if t, ok := f.(TableField); ok && isStructType(et) {
+			out := reflect.MakeSlice(v.Type(), len(t), len(t))
+			for i, row := range t {
+				if err := unmarshalRecordInto(row, out.Index(i)); err != nil {
+					return err
+				}
+			}
+			v.Set(out)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot unmarshal %T into %s", f, v.Type())
+}
+
+// setInt は桁あふれを確認して整数を設定する
+func setInt(v reflect.Value, i int64) error {
+	if v.OverflowInt(i) {
+		return fmt.Errorf("value %d overflows %s", i, v.Type())
+	}
+	v.SetInt(i)
+	return nil
+}
+
+// setUint は負の数と桁あふれを確認して符号なし整数を設定する
+func setUint(v reflect.Value, i int64) error {
+	if i < 0 || v.OverflowUint(uint64(i)) {
+		return fmt.Errorf("value %d overflows %s", i, v.Type())
+	}
+	v.SetUint(uint64(i))
+	return nil
+}
+
+//-unmarshal
+
+// isFieldType はパッケージで定義されたフィールド型かどうかを判定する
+func isFieldType(t reflect.Type) bool {
+	switch reflect.Zero(t).Interface().(type) {
+	case SingleLineTextField, MultiLineTextField, RichTextField, RadioButtonField,
+		SingleSelectField, LinkField, StatusField, RecordNumberField, IDField,
+		CalcField, RevisionField, NumberField, NullNumberField, DecimalField, CheckBoxField, MultiSelectField,
+		CategoryField, DateField, DateTimeField, TimeField, FileField,
+		[]*UserField, *UserField, UserField, AssigneeField,
+		[]*OrganizationField, []*GroupField, TableField, UnknownField:
+		return true
+	}
+	return false
+}
+
+// fieldDecimal は数値を表すフィールドの値を Decimal で返す
+func fieldDecimal(f Field) (Decimal, error) {
+	switch f := f.(type) {
+	case NumberField:
+		return NewDecimalFromInt(int64(f)), nil
+	case NullNumberField:
+		return Decimal{}, nil
+	case DecimalField:
+		return f.Decimal, nil
+	case CalcField:
+		return f.Decimal()
+	}
+	return Decimal{}, fmt.Errorf("cannot unmarshal %T into number", f)
+}
+
+func isStructType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && t != decimalType && t != dateType
+}
@@ -0,0 +1,281 @@
+package kintone
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type testOrder struct {
+	RowID    string `kintone:"$id"`
+	Product  string `kintone:"product"`
+	Quantity int    `kintone:"quantity"`
+}
+
+type testCustomer struct {
+	ID       int                 `kintone:"$id"`
+	Revision int                 `kintone:"$revision"`
+	Name     string              `kintone:"name"`
+	Memo     MultiLineTextField  `kintone:"memo"`
+	Age      NumberField         `kintone:"age"`
+	Birthday *time.Time          `kintone:"birthday,date"`
+	Tags     CheckBoxField       `kintone:"tags"`
+	Members  []*UserField        `kintone:"members"`
+	Files    FileField           `kintone:"files"`
+	Creator  UserField           `kintone:"creator"`
+	Orders   []*testOrder        `kintone:"orders"`
+	Note     string              `kintone:"note,omitempty"`
+	Ignored  string              `kintone:"-"`
+	Select   SingleSelectField   `kintone:"select"`
+	Updated  time.Time           `kintone:"updated"`
+	Groups   []*GroupField       `kintone:"groups"`
+	Category []string            `kintone:"category"`
+	Extra    map[string]struct{} // タグ無しは無視
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := []byte(`
+		{
+			"$id": {"type": "__ID__", "value": "10"},
+			"$revision": {"type": "__REVISION__", "value": "3"},
+			"name": {"type": "SINGLE_LINE_TEXT", "value": "佐藤"},
+			"memo": {"type": "MULTI_LINE_TEXT", "value": "a\nb"},
+			"age": {"type": "NUMBER", "value": "20"},
+			"birthday": {"type": "DATE", "value": "2000-01-02"},
+			"tags": {"type": "CHECK_BOX", "value": ["a", "b"]},
+			"members": {"type": "USER_SELECT", "value": [{"code": "sato", "name": "佐藤"}]},
+			"files": {"type": "FILE", "value": [{"contentType": "text/plain", "fileKey": "key", "name": "a.txt", "size": "1"}]},
+			"creator": {"type": "CREATOR", "value": {"code": "sato", "name": "佐藤"}},
+			"select": {"type": "DROP_DOWN", "value": "x"},
+			"updated": {"type": "UPDATED_TIME", "value": "2014-02-17T02:35:00Z"},
+			"category": {"type": "CATEGORY", "value": ["c1"]},
+			"orders": {
+				"type": "SUBTABLE",
+				"value": [
+					{
+						"id": "100",
+						"value": {
+							"product": {"type": "SINGLE_LINE_TEXT", "value": "pen"},
+							"quantity": {"type": "NUMBER", "value": "2"}
+						}
+					}
+				]
+			}
+		}
+	`)
+
+	var r Record
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	r.ID = "10"
+
+	var c testCustomer
+	err = Unmarshal(&r, &c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if c.ID != 10 || c.Revision != 3 || c.Name != "佐藤" || c.Age != 20 || c.Memo != "a\nb" {
+		t.Errorf("unexpected: %+v", c)
+		return
+	}
+	if c.Birthday == nil || c.Birthday.Format("2006-01-02") != "2000-01-02" {
+		t.Errorf("unexpected birthday: %v", c.Birthday)
+	}
+	if len(c.Tags) != 2 || len(c.Members) != 1 || c.Members[0].Code != "sato" || len(c.Files) != 1 {
+		t.Errorf("unexpected: %+v", c)
+	}
+	if c.Creator.Code != "sato" || c.Select.String() != "x" || c.Updated.IsZero() || len(c.Category) != 1 {
+		t.Errorf("unexpected: %+v", c)
+	}
+	if len(c.Orders) != 1 || c.Orders[0].RowID != "100" || c.Orders[0].Product != "pen" || c.Orders[0].Quantity != 2 {
+		t.Errorf("unexpected orders: %+v", c.Orders)
+	}
+}
+
+func TestUnmarshalNullDate(t *testing.T) {
+	r := &Record{Fields: Fields{"birthday": DateField{}}}
+	c := testCustomer{Birthday: &time.Time{}}
+	err := Unmarshal(r, &c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if c.Birthday != nil {
+		t.Errorf("expected: nil, actual: %v", c.Birthday)
+	}
+
+	// time.Time には空の日付をゼロ値で入れる
+	var v struct {
+		Birthday time.Time `kintone:"birthday"`
+	}
+	v.Birthday = time.Now()
+	if err := Unmarshal(r, &v); err != nil {
+		t.Error(err)
+		return
+	}
+	if !v.Birthday.IsZero() {
+		t.Errorf("expected: zero, actual: %v", v.Birthday)
+	}
+}
+
+func TestUnmarshalOverflow(t *testing.T) {
+	tests := []struct {
+		field Field
+		v     interface{}
+	}{
+		{NumberField(300), &struct {
+			N int8 `kintone:"n"`
+		}{}},
+		{NumberField(-1), &struct {
+			N uint `kintone:"n"`
+		}{}},
+		{DecimalField{MustParseDecimal("-1")}, &struct {
+			N uint64 `kintone:"n"`
+		}{}},
+		{SingleLineTextField("70000"), &struct {
+			N uint16 `kintone:"n"`
+		}{}},
+	}
+	for _, test := range tests {
+		r := &Record{Fields: Fields{"n": test.field}}
+		if err := Unmarshal(r, test.v); err == nil {
+			t.Errorf("%#v: overflow should be an error: %+v", test.field, test.v)
+		}
+	}
+
+	var v struct {
+		N int8 `kintone:"n"`
+	}
+	if err := Unmarshal(&Record{Fields: Fields{"n": NumberField(-128)}}, &v); err != nil || v.N != -128 {
+		t.Errorf("unexpected: %d, %v", v.N, err)
+	}
+}
+
+func TestUnmarshalTypeMismatch(t *testing.T) {
+	r := &Record{Fields: Fields{"age": CheckBoxField{"a"}}}
+	var c testCustomer
+	err := Unmarshal(r, &c)
+	if err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	birthday := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+	c := testCustomer{
+		ID:       10,
+		Revision: 3,
+		Name:     "佐藤",
+		Age:      20,
+		Birthday: &birthday,
+		Members:  []*UserField{{Code: "sato"}},
+		Orders:   []*testOrder{{RowID: "100", Product: "pen", Quantity: 2}},
+		Ignored:  "ignored",
+	}
+
+	r, err := Marshal(&c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if r.ID != "10" {
+		t.Errorf("expected: 10, actual: %s", r.ID)
+	}
+
+	for _, code := range []string{"$id", "$revision", "note", "Ignored", "Extra"} {
+		if _, ok := r.Fields[code]; ok {
+			t.Errorf("%s should not be marshaled", code)
+		}
+	}
+
+	if v, ok := r.Fields["birthday"].(DateField); !ok || v.String() != "2000-01-02" {
+		t.Errorf("unexpected birthday: %#v", r.Fields["birthday"])
+	}
+
+	// ゼロ値の日時は空の値として送信する
+	if v, ok := r.Fields["updated"].(DateTimeField); !ok || !v.IsNull() {
+		t.Errorf("unexpected updated: %#v", r.Fields["updated"])
+	}
+
+	table, ok := r.Fields["orders"].(TableField)
+	if !ok || len(table) != 1 || table[0].ID != "100" {
+		t.Errorf("unexpected orders: %#v", r.Fields["orders"])
+		return
+	}
+
+	actual, err := json.Marshal(table[0].Fields)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected := []byte(`{"product":{"value":"pen"},"quantity":{"value":"2"}}`)
+	if !jsonEqual(expected, actual) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(actual))
+	}
+}
+
+func TestMarshalRecords(t *testing.T) {
+	orders := []testOrder{{Product: "pen"}, {Product: "ink"}}
+	rs, err := MarshalRecords(orders)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(rs) != 2 || rs[1].Fields["product"] != SingleLineTextField("ink") {
+		t.Errorf("unexpected: %v", rs)
+		return
+	}
+
+	var out []*testOrder
+	err = UnmarshalRecords(rs, &out)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(out) != 2 || out[0].Product != "pen" {
+		t.Errorf("unexpected: %v", out)
+	}
+}
+
+func TestMarshalNull(t *testing.T) {
+	type record struct {
+		Count    *int       `kintone:"count"`
+		Due      *time.Time `kintone:"due,date"`
+		Start    *time.Time `kintone:"start,time"`
+		Name     *string    `kintone:"name"`
+		Optional *string    `kintone:"optional,omitempty"`
+	}
+
+	r, err := Marshal(&record{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, code := range []string{"count", "due", "start", "name"} {
+		f, ok := r.Fields[code]
+		if !ok || !IsNull(f) {
+			t.Errorf("%s should be null: %#v", code, f)
+		}
+	}
+
+	if _, ok := r.Fields["optional"]; ok {
+		t.Error("optional should be omitted")
+	}
+
+	var v record
+	err = Unmarshal(r, &v)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if v.Count != nil || v.Due != nil || v.Start != nil || v.Name != nil {
+		t.Errorf("unexpected: %#v", v)
+	}
+}
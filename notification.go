@@ -0,0 +1,131 @@
+package kintone
+
+import "encoding/json"
+
+// Notifications はアプリの通知の設定
+type Notifications struct {
+	General   *GeneralNotifications    `json:"general"`
+	PerRecord []*PerRecordNotification `json:"perRecord"`
+	Reminder  *ReminderNotifications   `json:"reminder"`
+}
+
+// GeneralNotifications はアプリの条件通知の設定
+type GeneralNotifications struct {
+	Notifications     []*GeneralNotification `json:"notifications"`
+	NotifyToCommenter bool                   `json:"notifyToCommenter"` // コメントを書き込んだユーザーに通知するかどうか
+}
+
+// GeneralNotification は通知先ごとのアプリの条件通知の設定
+type GeneralNotification struct {
+	Entity        Entity `json:"entity"`
+	IncludeSubs   bool   `json:"includeSubs"`
+	RecordAdded   bool   `json:"recordAdded"`
+	RecordEdited  bool   `json:"recordEdited"`
+	CommentAdded  bool   `json:"commentAdded"`
+	StatusChanged bool   `json:"statusChanged"`
+	FileImported  bool   `json:"fileImported"`
+}
+
+// NotificationTarget は通知先
+type NotificationTarget struct {
+	Entity      Entity `json:"entity"`
+	IncludeSubs bool   `json:"includeSubs"`
+}
+
+// PerRecordNotification はレコードの条件通知の設定
+type PerRecordNotification struct {
+	FilterCond string                `json:"filterCond"`
+	Title      string                `json:"title"`
+	Targets    []*NotificationTarget `json:"targets"`
+}
+
+// ReminderNotifications はリマインダーの条件通知の設定
+type ReminderNotifications struct {
+	Notifications []*ReminderNotification `json:"notifications"`
+	Timezone      string                  `json:"timezone"`
+}
+
+// ReminderNotification はリマインダーの条件通知
+type ReminderNotification struct {
+	Timing     ReminderTiming        `json:"timing"`
+	FilterCond string                `json:"filterCond"`
+	Title      string                `json:"title"`
+	Targets    []*NotificationTarget `json:"targets"`
+}
+
+// ReminderTiming はリマインダーの通知タイミング
+// 日付・日時フィールド Code の値から DaysLater 日後の Time（HH:mm）に通知する
+// 日時フィールドの場合は Time の代わりに HoursLater、MinutesLater を指定できる
+type ReminderTiming struct {
+	Code         string `json:"code"`
+	DaysLater    int    `json:"daysLater,string"`
+	HoursLater   int    `json:"hoursLater,string"`
+	MinutesLater int    `json:"minutesLater,string"`
+	Time         string `json:"time,omitempty"`
+}
+
+// MarshalJSON は Time を指定した場合のみ HoursLater、MinutesLater を送信しない
+// Time が空の場合は 0 でも送信する
+func (t ReminderTiming) MarshalJSON() ([]byte, error) {
+	type raw ReminderTiming
+	if t.Time == "" {
+		return json.Marshal(raw(t))
+	}
+	return json.Marshal(struct {
+		Code      string `json:"code"`
+		DaysLater int    `json:"daysLater,string"`
+		Time      string `json:"time"`
+	}{t.Code, t.DaysLater, t.Time})
+}
+
+// ReadNotifications はアプリの通知の設定を返す
+func (repo *Repository) ReadNotifications(appID int) (*Notifications, error) {
+	var n Notifications
+
+	n.General = &GeneralNotifications{}
+	err := repo.readAppSetting(APIEndpointGeneralNotifications, appID, n.General)
+	if err != nil {
+		return nil, err
+	}
+
+	perRecord := struct {
+		Notifications []*PerRecordNotification `json:"notifications"`
+	}{}
+	err = repo.readAppSetting(APIEndpointPerRecordNotifications, appID, &perRecord)
+	if err != nil {
+		return nil, err
+	}
+	n.PerRecord = perRecord.Notifications
+
+	n.Reminder = &ReminderNotifications{}
+	err = repo.readAppSetting(APIEndpointReminderNotifications, appID, n.Reminder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// UpdateGeneralNotifications はアプリの条件通知の設定を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateGeneralNotifications(appID int, n *GeneralNotifications, revision string) (string, error) {
+	return repo.updateAppSetting(APIEndpointPreviewGeneralNotifications, appID, n, revision)
+}
+
+// UpdatePerRecordNotifications はレコードの条件通知の設定を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdatePerRecordNotifications(appID int, ns []*PerRecordNotification, revision string) (string, error) {
+	raw := struct {
+		Notifications []*PerRecordNotification `json:"notifications"`
+	}{ns}
+	return repo.updateAppSetting(APIEndpointPreviewPerRecordNotifications, appID, &raw, revision)
+}
+
+// UpdateReminderNotifications はリマインダーの条件通知の設定を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateReminderNotifications(appID int, n *ReminderNotifications, revision string) (string, error) {
+	return repo.updateAppSetting(APIEndpointPreviewReminderNotifications, appID, n, revision)
+}
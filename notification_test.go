@@ -0,0 +1,32 @@
+package kintone
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReminderTimingMarshalJSON(t *testing.T) {
+	tests := []struct {
+		timing   ReminderTiming
+		expected string
+	}{
+		{ReminderTiming{Code: "期限", DaysLater: -1, Time: "09:00"}, `{"code": "期限", "daysLater": "-1", "time": "09:00"}`},
+		{ReminderTiming{Code: "期限", DaysLater: 0}, `{"code": "期限", "daysLater": "0", "hoursLater": "0", "minutesLater": "0"}`},
+		{ReminderTiming{Code: "期限", HoursLater: -2, MinutesLater: 30}, `{"code": "期限", "daysLater": "0", "hoursLater": "-2", "minutesLater": "30"}`},
+	}
+	for _, test := range tests {
+		// 構造体のフィールドとしてエンコードされる場合も同じ
+		data, err := json.Marshal(&ReminderNotification{Timing: test.timing})
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		var raw struct {
+			Timing json.RawMessage `json:"timing"`
+		}
+		json.Unmarshal(data, &raw)
+		if !jsonEqual([]byte(test.expected), raw.Timing) {
+			t.Errorf("expected: %s, actual: %s", test.expected, raw.Timing)
+		}
+	}
+}
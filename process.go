@@ -0,0 +1,214 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// ProcessManagement はプロセス管理の設定
+type ProcessManagement struct {
+	Enable  bool                     `json:"enable"`
+	States  map[string]*ProcessState `json:"states"`
+	Actions []*ProcessAction         `json:"actions"`
+}
+
+// ProcessState はプロセス管理のステータス
+type ProcessState struct {
+	Name     string           `json:"name"`
+	Index    int              `json:"index,string"`
+	Assignee *ProcessAssignee `json:"assignee,omitempty"`
+}
+
+// ProcessAssignee はステータスの作業者の設定
+type ProcessAssignee struct {
+	Type     string           `json:"type"` // ONE, ALL, ANY
+	Entities []*ProcessEntity `json:"entities"`
+}
+
+// ProcessEntity はステータスの作業者
+type ProcessEntity struct {
+	Entity      Entity `json:"entity"`
+	IncludeSubs bool   `json:"includeSubs"`
+}
+
+// ProcessAction はプロセス管理のアクション
+type ProcessAction struct {
+	Name       string `json:"name"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	FilterCond string `json:"filterCond"` // アクションを実行できる条件
+}
+
+// ReadProcessManagement はアプリのプロセス管理の設定を返す
+func (repo *Repository) ReadProcessManagement(appID int) (*ProcessManagement, error) {
+	var p ProcessManagement
+	err := repo.readAppSetting(APIEndpointProcess, appID, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdateProcessManagement はアプリのプロセス管理の設定を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateProcessManagement(appID int, p *ProcessManagement, revision string) (string, error) {
+	return repo.updateAppSetting(APIEndpointPreviewProcess, appID, p, revision)
+}
+
+// ReadPreviewProcessManagement は運用環境に反映する前のプロセス管理の設定と revision を返す
+func (repo *Repository) ReadPreviewProcessManagement(appID int) (*ProcessManagement, string, error) {
+	raw := struct {
+		ProcessManagement
+		Revision string `json:"revision"`
+	}{}
+	err := repo.readAppSetting(APIEndpointPreviewProcess, appID, &raw)
+	if err != nil {
+		return nil, "", err
+	}
+	return &raw.ProcessManagement, raw.Revision, nil
+}
+
+// StatusUpdate はレコードのステータスの更新
+type StatusUpdate struct {
+	ID       string `json:"id"`
+	Action   string `json:"action"`             // 実行するアクション名
+	Assignee string `json:"assignee,omitempty"` // 次のステータスの作業者を選択する場合のログイン名
+	Revision string `json:"revision,omitempty"` // 空の場合は revision を確認しない
+}
+
+// UpdateStatus はアクションを実行してレコードのステータスを更新し、更新後の revision を返す
+func (repo *Repository) UpdateStatus(ctx context.Context, appID int, u *StatusUpdate) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	body, err := json.Marshal(struct {
+		App int `json:"app"`
+		*StatusUpdate
+	}{appID, u})
+	if err != nil {
+		return "", err
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", errors.New("canceled")
+	default:
+	}
+
+	data, err := repo.Client.put(APIEndpointRecordStatus, body)
+	if err != nil {
+		return "", err
+	}
+	return unmarshalRevision(data)
+}
+
+// UpdateStatuses は複数のレコードのステータスを更新する
+// 100 件ずつ並行して更新し、通信エラーや 5xx、429 で失敗した場合は MaxRetry 回まで再試行する
+func (repo *Repository) UpdateStatuses(ctx context.Context, appID int, us ...*StatusUpdate) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < len(us); i += 100 {
+		end := i + 100
+		if end > len(us) {
+			end = len(us)
+		}
+		_us := us[i:end]
+		eg.Go(func() error {
+			return repo.updateStatusesWithRetry(ctx, appID, _us)
+		})
+	}
+
+	return eg.Wait()
+}
+
+// update 100 statuses with retry
+// アクションの実行は冪等ではないため、4xx のエラーは再試行しない
+func (repo *Repository) updateStatusesWithRetry(ctx context.Context, appID int, us []*StatusUpdate) error {
+	if appID == 0 {
+		return errors.New("appID is required")
+	}
+
+	body, err := json.Marshal(struct {
+		App     int             `json:"app"`
+		Records []*StatusUpdate `json:"records"`
+	}{appID, us})
+	if err != nil {
+		return err
+	}
+
+	var retryCount int
+
+	for {
+		err = repo.updateStatuses(ctx, body)
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+
+		retryCount++
+		if retryCount > repo.MaxRetry {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second * RetryInterval):
+		}
+	}
+}
+
+func (repo *Repository) updateStatuses(ctx context.Context, body []byte) error {
+	select {
+	case repo.Token <- struct{}{}: // acquire token
+		defer func() {
+			<-repo.Token
+		}()
+	case <-ctx.Done(): // cancelled
+		return ctx.Err()
+	}
+
+	_, err := repo.Client.put(APIEndpointRecordsStatus, body)
+	return err
+}
+
+// UpdateAssignees はレコードの作業者をログイン名 codes のユーザーに更新し、更新後の revision を返す
+// revision が空の場合は revision を確認しない
+func (repo *Repository) UpdateAssignees(ctx context.Context, appID int, id string, codes []string, revision string) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if codes == nil {
+		// 作業者を空にする
+		codes = []string{}
+	}
+
+	body, err := json.Marshal(struct {
+		App       int      `json:"app"`
+		ID        string   `json:"id"`
+		Assignees []string `json:"assignees"`
+		Revision  string   `json:"revision,omitempty"`
+	}{appID, id, codes, revision})
+	if err != nil {
+		return "", err
+	}
+
+	select {
+	case <-ctx.Done():
+		return "", errors.New("canceled")
+	default:
+	}
+
+	data, err := repo.Client.put(APIEndpointRecordAssignees, body)
+	if err != nil {
+		return "", err
+	}
+	return unmarshalRevision(data)
+}
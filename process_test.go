@@ -0,0 +1,168 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const testProcess = `{
+	"enable": true,
+	"states": {
+		"未処理": {"name": "未処理", "index": "0", "assignee": {"type": "ONE", "entities": []}},
+		"処理中": {"name": "処理中", "index": "1", "assignee": {"type": "ONE", "entities": [{"entity": {"type": "FIELD_ENTITY", "code": "担当者"}, "includeSubs": false}]}},
+		"完了": {"name": "完了", "index": "2", "assignee": {"type": "ONE", "entities": []}}
+	},
+	"actions": [
+		{"name": "処理開始", "from": "未処理", "to": "処理中", "filterCond": ""},
+		{"name": "完了する", "from": "処理中", "to": "完了", "filterCond": "金額 <= \"10000\""}
+	],
+	"revision": "3"
+}`
+
+func TestReadProcessManagement(t *testing.T) {
+	repo, _ := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(testProcess), nil
+	})
+
+	p, revision, err := repo.ReadPreviewProcessManagement(1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if revision != "3" || !p.Enable || len(p.States) != 3 || len(p.Actions) != 2 {
+		t.Errorf("unexpected process management: %s %#v", revision, p)
+	}
+	if s := p.States["処理中"]; s.Index != 1 || s.Assignee.Entities[0].Entity.Code != "担当者" {
+		t.Errorf("unexpected state: %#v", s)
+	}
+}
+
+func TestUpdateStatus(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"revision": "5"}`), nil
+	})
+
+	revision, err := repo.UpdateStatus(context.Background(), 1, &StatusUpdate{ID: "3", Action: "処理開始", Assignee: "sato", Revision: "4"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if revision != "5" {
+		t.Errorf("expected: 5, actual: %s", revision)
+	}
+	expected := []byte(`{"app": 1, "id": "3", "action": "処理開始", "assignee": "sato", "revision": "4"}`)
+	if req := c.requests[0]; req.Method != "PUT" || req.Path != APIEndpointRecordStatus || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+
+	if _, err := repo.UpdateAssignees(context.Background(), 1, "3", nil, ""); err != nil {
+		t.Error(err)
+		return
+	}
+	expected = []byte(`{"app": 1, "id": "3", "assignees": []}`)
+	if req := c.requests[1]; req.Path != APIEndpointRecordAssignees || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+}
+
+func TestUpdateStatuses(t *testing.T) {
+	repo, c := newFakeRepository(nil)
+
+	us := make([]*StatusUpdate, 150)
+	for i := range us {
+		us[i] = &StatusUpdate{ID: fmt.Sprint(i + 1), Action: "完了する"}
+	}
+	if err := repo.UpdateStatuses(context.Background(), 1, us...); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(c.requests) != 2 {
+		t.Errorf("unexpected requests: %d", len(c.requests))
+		return
+	}
+	var n int
+	for _, req := range c.requests {
+		var body struct {
+			Records []*StatusUpdate `json:"records"`
+		}
+		json.Unmarshal(req.Body, &body)
+		if req.Path != APIEndpointRecordsStatus || len(body.Records) > 100 {
+			t.Errorf("unexpected request: %s %d", req.Path, len(body.Records))
+		}
+		n += len(body.Records)
+	}
+	if n != 150 {
+		t.Errorf("expected: 150, actual: %d", n)
+	}
+}
+
+func TestUpdateStatusesNoRetryOnClientError(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return nil, &resError{HTTPStatusCode: 400, Code: "GAIA_IL03", Message: "ステータスの変更に失敗しました。"}
+	})
+	repo.MaxRetry = 3
+
+	err := repo.UpdateStatuses(context.Background(), 1, &StatusUpdate{ID: "1", Action: "完了する"})
+	if err == nil {
+		t.Error("error should be returned")
+	}
+	if len(c.requests) != 1 {
+		t.Errorf("expected: 1, actual: %d", len(c.requests))
+	}
+	if len(repo.Token) != 0 {
+		t.Error("token should be released")
+	}
+}
+
+func TestUpdateStatusesCanceled(t *testing.T) {
+	repo, _ := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return nil, &resError{HTTPStatusCode: 503}
+	})
+	repo.MaxRetry = 3
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- repo.UpdateStatuses(ctx, 1, &StatusUpdate{ID: "1", Action: "完了する"})
+	}()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("retry should stop when the context is canceled")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{&url.Error{Op: "Put", URL: "https://example.cybozu.com", Err: errors.New("connection reset by peer")}, true},
+		{errors.New("canceled"), false},
+		{context.Canceled, false},
+		{&json.SyntaxError{}, false},
+		{&resError{HTTPStatusCode: 500, Code: "GAIA_UN01"}, true},
+		{&resError{HTTPStatusCode: 503}, true},
+		{&resError{HTTPStatusCode: 429, Code: "GAIA_TM12"}, true},
+		{&resError{HTTPStatusCode: 400, Code: "GAIA_IL03"}, false},
+		{&resError{HTTPStatusCode: 409, Code: "GAIA_CO02"}, false},
+		{errors.Wrap(&resError{HTTPStatusCode: 404, Code: "GAIA_RE01"}, "update failed"), false},
+	}
+	for _, tt := range tests {
+		if actual := isRetryable(tt.err); actual != tt.expected {
+			t.Errorf("%v: expected: %v, actual: %v", tt.err, tt.expected, actual)
+		}
+	}
+}
@@ -2,6 +2,10 @@ package kintone
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Record ...
@@ -25,14 +29,17 @@ func (r *Record) UnmarshalJSON(data []byte) error {
 
 	fs := make(Fields)
 
+	// $id が含まれている場合はレコード番号より優先する
+	var recordID, recordNumber string
+
 	for code, raw := range raws {
-		if raw.Type == FieldTypeRecordNumber {
-			var id string
-			err := json.Unmarshal(*raw.Value, &id)
-			if err != nil {
-				return err
+		// value が null の場合はフィールドタイプに対応する空の値を設定する
+		if raw.Value == nil {
+			f := NewNullField(raw.Type)
+			if f == nil {
+				f = UnknownField{Type: raw.Type}
 			}
-			r.ID = id
+			fs[code] = f
 			continue
 		}
 
@@ -54,10 +61,7 @@ func (r *Record) UnmarshalJSON(data []byte) error {
 			f = _f
 		case FieldTypeRadioButton:
 			var _f RadioButtonField
-			// valueの値としてnullが入ってくる可能性があるため以下のハンドリングが必要
-			if raw.Value != nil {
-				err = json.Unmarshal(*raw.Value, &_f)
-			}
+			err = json.Unmarshal(*raw.Value, &_f)
 			f = _f
 		case FieldTypeLink:
 			var _f LinkField
@@ -65,31 +69,28 @@ func (r *Record) UnmarshalJSON(data []byte) error {
 			f = _f
 		case FieldTypeSingleSelect:
 			var _f SingleSelectField
-			// valueの値としてnullが入ってくる可能性があるため以下のハンドリングが必要
-			if raw.Value != nil {
-				err = json.Unmarshal(*raw.Value, &_f)
-			}
+			err = json.Unmarshal(*raw.Value, &_f)
 			f = _f
 		case FieldTypeStatus:
 			var _f StatusField
 			err = json.Unmarshal(*raw.Value, &_f)
 			f = _f
-		// case FieldTypeRecordNumber:
-		// 	var _f RecordNumberField
-		// 	err = json.Unmarshal(*raw.Value, &_f)
-		// 	f = _f
-		// case FieldTypeID:
-		// 	var _f IDField
-		// 	err = json.Unmarshal(*raw.Value, &_f)
-		// 	f = _f
+		case FieldTypeRecordNumber:
+			var _f RecordNumberField
+			err = json.Unmarshal(*raw.Value, &_f)
+			recordNumber = string(_f)
+			f = _f
+		case FieldTypeID:
+			var _f IDField
+			err = json.Unmarshal(*raw.Value, &_f)
+			recordID = string(_f)
+			f = _f
 		case FieldTypeCalc:
 			var _f CalcField
 			err = json.Unmarshal(*raw.Value, &_f)
 			f = _f
 		case FieldTypeNumber:
-			var _f NumberField
-			err = json.Unmarshal(*raw.Value, &_f)
-			f = _f
+			f, err = decodeNumber(*raw.Value)
 		case FieldTypeCheckBox:
 			var _f CheckBoxField
 			err = json.Unmarshal(*raw.Value, &_f)
@@ -104,32 +105,33 @@ func (r *Record) UnmarshalJSON(data []byte) error {
 			f = _f
 		case FieldTypeDate:
 			var _f DateField
-			// valueの値としてnullが入ってくる可能性があるため以下のハンドリングが必要
-			if raw.Value != nil {
-				err = json.Unmarshal(*raw.Value, &_f)
-			}
+			err = json.Unmarshal(*raw.Value, &_f)
 			f = _f
-		case FieldTypeDateTime, FieldTypeCreatedDateTime, FieldTypeUpdatedDateTime:
+		case FieldTypeDateTime:
 			var _f DateTimeField
-			// valueの値としてnullが入ってくる可能性があるため以下のハンドリングが必要
-			if raw.Value != nil {
-				err = json.Unmarshal(*raw.Value, &_f)
-			}
+			err = json.Unmarshal(*raw.Value, &_f)
+			f = _f
+		case FieldTypeCreatedDateTime, FieldTypeUpdatedDateTime:
+			var _f DateTimeField
+			err = json.Unmarshal(*raw.Value, &_f)
+			_f.fieldType = raw.Type
 			f = _f
 		case FieldTypeTime:
 			var _f TimeField
-			// valueの値としてnullが入ってくる可能性があるため以下のハンドリングが必要
-			if raw.Value != nil {
-				err = json.Unmarshal(*raw.Value, &_f)
-			}
+			err = json.Unmarshal(*raw.Value, &_f)
 			f = _f
-		case FieldTypeUsers, FieldTypeAssignee:
+		case FieldTypeUsers:
 			var _f []*UserField
 			err = json.Unmarshal(*raw.Value, &_f)
 			f = _f
+		case FieldTypeAssignee:
+			var _f AssigneeField
+			err = json.Unmarshal(*raw.Value, &_f)
+			f = _f
 		case FieldTypeCreator, FieldTypeModifier:
 			var _f UserField
 			err = json.Unmarshal(*raw.Value, &_f)
+			_f.fieldType = raw.Type
 			f = &_f
 		case FieldTypeOrganization:
 			var _f []*OrganizationField
@@ -152,7 +154,13 @@ func (r *Record) UnmarshalJSON(data []byte) error {
 			err = json.Unmarshal(*raw.Value, &_f)
 			f = _f
 		default:
-			continue
+			// 登録されたデコーダーが無いタイプは value をそのまま保持する
+			dec := fieldDecoder(raw.Type)
+			if dec == nil {
+				f = UnknownField{Type: raw.Type, Value: *raw.Value}
+				break
+			}
+			f, err = dec(*raw.Value)
 		}
 
 		if err != nil {
@@ -162,11 +170,108 @@ func (r *Record) UnmarshalJSON(data []byte) error {
 		fs[code] = f
 	}
 
+	switch {
+	case recordID != "":
+		r.ID = recordID
+	case recordNumber != "":
+		r.ID = recordIDFromNumber(recordNumber)
+	}
+
 	r.Fields = fs
 
 	return nil
 }
 
+// MarshalJSON はフィールドタイプを含む kintone の形式でエンコードする
+// UnmarshalJSON で元のレコードに戻せるため、レコードをファイルに保存する場合などに使う
+func (r Record) MarshalJSON() ([]byte, error) {
+	obj, err := marshalRecordFields(r.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	// レコード番号から ID を取得した場合など、$id が無ければ追加する
+	if _, ok := obj[fieldCodeID]; !ok && r.ID != "" {
+		obj[fieldCodeID] = typedValue{FieldTypeID, r.ID}
+	}
+
+	return json.Marshal(obj)
+}
+
+// typedValue はフィールドタイプを含む kintone の形式のフィールド
+type typedValue struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+func marshalRecordFields(fs Fields) (map[string]typedValue, error) {
+	obj := make(map[string]typedValue, len(fs))
+	for code, f := range fs {
+		t := FieldTypeOf(f)
+		if t == "" {
+			return nil, fmt.Errorf("kintone: unknown field type of %s: %T", code, f)
+		}
+
+		var v interface{} = f
+		switch f := f.(type) {
+		case TableField:
+			type row struct {
+				ID    string                `json:"id"`
+				Value map[string]typedValue `json:"value"`
+			}
+			rows := make([]row, len(f))
+			for i, r := range f {
+				value, err := marshalRecordFields(r.Fields)
+				if err != nil {
+					return nil, err
+				}
+				rows[i] = row{r.ID, value}
+			}
+			v = rows
+		}
+
+		obj[code] = typedValue{t, v}
+	}
+	return obj, nil
+}
+
+// localize は日時フィールドの値を loc に変換する
+func (fs Fields) localize(loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	for code, f := range fs {
+		switch f := f.(type) {
+		case DateTimeField:
+			fs[code] = f.In(loc)
+		case TableField:
+			for _, r := range f {
+				r.Fields.localize(loc)
+			}
+		}
+	}
+}
+
+func localizeRecords(rs []*Record, loc *time.Location) {
+	for _, r := range rs {
+		r.Fields.localize(loc)
+	}
+}
+
+// recordIDFromNumber はレコード番号からレコード ID を取り出す
+// アプリコードが設定されている場合、レコード番号は "SALES-12" の形式になる
+func recordIDFromNumber(n string) string {
+	i := strings.LastIndex(n, "-")
+	if i < 0 {
+		return n
+	}
+	id := n[i+1:]
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return n
+	}
+	return id
+}
+
 // NewRecord ...
 func NewRecord(id string, fs Fields) *Record {
 	return &Record{ID: id, Fields: fs}
@@ -189,3 +294,84 @@ func sliceRecords(rs []*Record, i int) [][]*Record {
 	}
 	return out
 }
+
+//+writeFields
+
+// writeFields はレコードの登録・更新時に送信する形式でフィールドをエンコードする
+// $id やレコード番号など値を指定できないフィールドは送信せず、ユーザーや組織はコードのみを送信する
+type writeFields Fields
+
+// MarshalJSON ...
+func (fs writeFields) MarshalJSON() ([]byte, error) {
+	type value struct {
+		Value interface{} `json:"value"`
+	}
+
+	obj := make(map[string]value)
+	for code, f := range fs {
+		if !isWritableField(f) {
+			continue
+		}
+		obj[code] = value{writeValue(f)}
+	}
+
+	return json.Marshal(obj)
+}
+
+// isWritableField はレコードの登録・更新時に値を指定できるフィールドかどうかを返す
+func isWritableField(f Field) bool {
+	switch f := f.(type) {
+	case IDField, RevisionField, RecordNumberField, CalcField, StatusField, CategoryField, AssigneeField:
+		return false
+	case *UserField:
+		// 作成者・更新者
+		return !f.IsNull()
+	case DateTimeField:
+		// 作成日時・更新日時
+		return f.fieldType == "" || !f.IsNull()
+	}
+	return true
+}
+
+// entityCode はユーザー・組織・グループを指定する際の形式
+type entityCode struct {
+	Code string `json:"code"`
+}
+
+func writeValue(f Field) interface{} {
+	switch f := f.(type) {
+	case []*UserField:
+		codes := make([]entityCode, len(f))
+		for i, u := range f {
+			codes[i] = entityCode{u.Code}
+		}
+		return codes
+	case []*OrganizationField:
+		codes := make([]entityCode, len(f))
+		for i, o := range f {
+			codes[i] = entityCode{o.Code}
+		}
+		return codes
+	case []*GroupField:
+		codes := make([]entityCode, len(f))
+		for i, g := range f {
+			codes[i] = entityCode{g.Code}
+		}
+		return codes
+	case *UserField:
+		return entityCode{f.Code}
+	case TableField:
+		type row struct {
+			ID    string      `json:"id,omitempty"`
+			Value writeFields `json:"value"`
+		}
+		rows := make([]row, len(f))
+		for i, r := range f {
+			rows[i] = row{r.ID, writeFields(r.Fields)}
+		}
+		return rows
+	}
+	return f
+}
+
+//-writeFields
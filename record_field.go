@@ -3,8 +3,10 @@ package kintone
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -45,6 +47,216 @@ type Field interface {
 	// String() string
 }
 
+// Nullable は値が空（null）かどうかを返すフィールド
+type Nullable interface {
+	IsNull() bool
+}
+
+// IsNull はフィールドの値が空かどうかを返す
+// kintone では文字列の空文字、選択されていないユーザーなども値が空として扱われる
+func IsNull(f Field) bool {
+	if f == nil {
+		return true
+	}
+	if n, ok := f.(Nullable); ok {
+		return n.IsNull()
+	}
+	switch f := f.(type) {
+	case []*UserField:
+		return len(f) == 0
+	case []*OrganizationField:
+		return len(f) == 0
+	case []*GroupField:
+		return len(f) == 0
+	}
+	return false
+}
+
+// NewNullField はフィールドタイプに対応する空の値を返す
+// Fields に設定すると、更新時にフィールドの値を空にする
+func NewNullField(fieldType string) Field {
+	switch fieldType {
+	case FieldTypeSingleLineText:
+		return SingleLineTextField("")
+	case FieldTypeMultiLineText:
+		return MultiLineTextField("")
+	case FieldTypeRichText:
+		return RichTextField("")
+	case FieldTypeRadioButton:
+		return RadioButtonField("")
+	case FieldTypeSingleSelect:
+		return SingleSelectField{}
+	case FieldTypeLink:
+		return LinkField("")
+	case FieldTypeStatus:
+		return StatusField("")
+	case FieldTypeRecordNumber:
+		return RecordNumberField("")
+	case FieldTypeID:
+		return IDField("")
+	case FieldTypeRevision:
+		return RevisionField("")
+	case FieldTypeCalc:
+		return CalcField("")
+	case FieldTypeNumber:
+		return NullNumberField{}
+	case FieldTypeCheckBox:
+		return CheckBoxField{}
+	case FieldTypeMultiSelect:
+		return MultiSelectField{}
+	case FieldTypeCategory:
+		return CategoryField{}
+	case FieldTypeDate:
+		return DateField{}
+	case FieldTypeDateTime:
+		return DateTimeField{}
+	case FieldTypeCreatedDateTime, FieldTypeUpdatedDateTime:
+		return DateTimeField{fieldType: fieldType}
+	case FieldTypeTime:
+		return TimeField("")
+	case FieldTypeFile:
+		return FileField{}
+	case FieldTypeUsers:
+		return []*UserField{}
+	case FieldTypeAssignee:
+		return AssigneeField{}
+	case FieldTypeCreator, FieldTypeModifier:
+		return &UserField{fieldType: fieldType}
+	case FieldTypeOrganization:
+		return []*OrganizationField{}
+	case FieldTypeGroup:
+		return []*GroupField{}
+	case FieldTypeSubtable:
+		return TableField{}
+	}
+	return nil
+}
+
+// FieldTypeOf はフィールドのタイプ（"SINGLE_LINE_TEXT" など）を返す
+// 判定できない場合は空文字を返す
+func FieldTypeOf(f Field) string {
+	if t, ok := f.(interface{ FieldType() string }); ok {
+		return t.FieldType()
+	}
+	switch f := f.(type) {
+	case SingleLineTextField:
+		return FieldTypeSingleLineText
+	case MultiLineTextField:
+		return FieldTypeMultiLineText
+	case RichTextField:
+		return FieldTypeRichText
+	case RadioButtonField:
+		return FieldTypeRadioButton
+	case SingleSelectField:
+		return FieldTypeSingleSelect
+	case LinkField:
+		return FieldTypeLink
+	case StatusField:
+		return FieldTypeStatus
+	case RecordNumberField:
+		return FieldTypeRecordNumber
+	case IDField:
+		return FieldTypeID
+	case RevisionField:
+		return FieldTypeRevision
+	case CalcField:
+		return FieldTypeCalc
+	case NumberField, DecimalField:
+		return FieldTypeNumber
+	case CheckBoxField:
+		return FieldTypeCheckBox
+	case MultiSelectField:
+		return FieldTypeMultiSelect
+	case CategoryField:
+		return FieldTypeCategory
+	case DateField:
+		return FieldTypeDate
+	case DateTimeField:
+		if f.fieldType != "" {
+			// 作成日時・更新日時
+			return f.fieldType
+		}
+		return FieldTypeDateTime
+	case TimeField:
+		return FieldTypeTime
+	case FileField:
+		return FieldTypeFile
+	case []*UserField:
+		return FieldTypeUsers
+	case AssigneeField:
+		return FieldTypeAssignee
+	case *UserField:
+		if f != nil && f.fieldType != "" {
+			return f.fieldType
+		}
+		// デコードしていない場合は作成者として扱う
+		return FieldTypeCreator
+	case []*OrganizationField:
+		return FieldTypeOrganization
+	case []*GroupField:
+		return FieldTypeGroup
+	case TableField:
+		return FieldTypeSubtable
+	}
+	return ""
+}
+
+//+FieldDecoder
+
+// FieldDecoder は kintone の value を Field にデコードする
+type FieldDecoder func(data json.RawMessage) (Field, error)
+
+var (
+	fieldDecodersMu sync.RWMutex
+	fieldDecoders   = make(map[string]FieldDecoder)
+)
+
+// RegisterFieldType はパッケージが対応していないフィールドタイプのデコーダーを登録する
+// 登録されていないタイプのフィールドは UnknownField としてデコードされる
+// デコードした Field が FieldType() string を実装している場合、FieldTypeOf はその値を返す
+func RegisterFieldType(fieldType string, dec FieldDecoder) {
+	fieldDecodersMu.Lock()
+	defer fieldDecodersMu.Unlock()
+	fieldDecoders[fieldType] = dec
+}
+
+func fieldDecoder(fieldType string) FieldDecoder {
+	fieldDecodersMu.RLock()
+	defer fieldDecodersMu.RUnlock()
+	return fieldDecoders[fieldType]
+}
+
+// UnknownField はパッケージが対応していないタイプのフィールド
+// 受け取った value をそのまま保持し、送信時もそのまま送り返す
+type UnknownField struct {
+	Type  string
+	Value json.RawMessage
+}
+
+// FieldType ...
+func (f UnknownField) FieldType() string {
+	return f.Type
+}
+
+// MarshalJSON ...
+func (f UnknownField) MarshalJSON() ([]byte, error) {
+	if len(f.Value) == 0 {
+		return []byte("null"), nil
+	}
+	return f.Value, nil
+}
+
+// IsNull ...
+func (f UnknownField) IsNull() bool {
+	return len(f.Value) == 0 || string(f.Value) == "null"
+}
+
+func (f UnknownField) String() string {
+	return string(f.Value)
+}
+
+//-FieldDecoder
+
 // Fields ...
 type Fields map[string]Field
 
@@ -78,8 +290,9 @@ type RichTextField string
 type RadioButtonField string
 
 // SingleSelectField ...
+// Value が空の場合は null として扱う
 type SingleSelectField struct {
-	Value interface{} // string or nil
+	Value string
 }
 
 func NewSingleSelectField(str string) SingleSelectField {
@@ -99,17 +312,19 @@ func (f *SingleSelectField) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON ...
 func (f SingleSelectField) MarshalJSON() ([]byte, error) {
-	if f.Value != nil {
-		return json.Marshal(f.String())
+	if f.IsNull() {
+		return []byte("null"), nil
 	}
 	return json.Marshal(f.Value)
 }
 
 func (f SingleSelectField) String() string {
-	if f.Value == nil {
-		return ""
-	}
-	return fmt.Sprint(f.Value)
+	return f.Value
+}
+
+// IsNull ...
+func (f SingleSelectField) IsNull() bool {
+	return f.Value == ""
 }
 
 // LinkField ...
@@ -130,9 +345,42 @@ type CalcField string
 // RevisionField ...
 type RevisionField string
 
+// IsNull ...
+func (f SingleLineTextField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f MultiLineTextField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f RichTextField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f RadioButtonField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f LinkField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f StatusField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f RecordNumberField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f IDField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f CalcField) IsNull() bool { return f == "" }
+
+// IsNull ...
+func (f RevisionField) IsNull() bool { return f == "" }
+
 //-String
 
 //+NumberField ...
+
+// NumberField は整数の数値フィールド
+// 空の NUMBER は NullNumberField にデコードされる
 type NumberField int64
 
 func (f *NumberField) UnmarshalJSON(data []byte) error {
@@ -144,6 +392,7 @@ func (f *NumberField) UnmarshalJSON(data []byte) error {
 
 	// ブランクの場合は0を返す
 	if raw == "" {
+		*f = 0
 		return nil
 	}
 
@@ -160,8 +409,140 @@ func (f NumberField) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s)
 }
 
+// IsNull は常に false を返す
+// 空の NUMBER は NullNumberField にデコードされる
+func (f NumberField) IsNull() bool {
+	return false
+}
+
+// NullNumberField は空の数値フィールド
+// "" として送信される
+type NullNumberField struct{}
+
+// FieldType は NUMBER を返す
+func (NullNumberField) FieldType() string { return FieldTypeNumber }
+
+// IsNull は常に true を返す
+func (NullNumberField) IsNull() bool { return true }
+
+// String は空文字を返す
+func (NullNumberField) String() string { return "" }
+
+func (NullNumberField) MarshalJSON() ([]byte, error) {
+	return json.Marshal("")
+}
+
 //-NumberField ...
 
+//+DecimalField
+
+// DecimalField は小数や指数表記を含む数値フィールド
+// NUMBER の値が int64 で表現できない場合（"12.5", "-1e3" など）にデコードされる
+// ゼロ値は空（null）を表し、"" として送信される
+type DecimalField struct {
+	Decimal
+}
+
+// NewDecimalField ...
+func NewDecimalField(s string) (DecimalField, error) {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		return DecimalField{}, err
+	}
+	return DecimalField{d}, nil
+}
+
+// decodeNumber は NUMBER の値をデコードする
+// 整数は NumberField、空の値は NullNumberField、それ以外は DecimalField を返す
+func decodeNumber(data []byte) (Field, error) {
+	var raw string
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw == "" {
+		return NullNumberField{}, nil
+	}
+
+	if i, err := strconv.ParseInt(raw, 10, 64); err == nil && strconv.FormatInt(i, 10) == raw {
+		return NumberField(i), nil
+	}
+
+	d, err := ParseDecimal(raw)
+	if err != nil {
+		return nil, err
+	}
+	return DecimalField{d}, nil
+}
+
+//-DecimalField
+
+//+CalcField
+
+// CalcField の表示形式
+const (
+	CalcFormatNumber        = "NUMBER"
+	CalcFormatNumberDigit   = "NUMBER_DIGIT"
+	CalcFormatDateTime      = "DATETIME"
+	CalcFormatDate          = "DATE"
+	CalcFormatTime          = "TIME"
+	CalcFormatHourMinute    = "HOUR_MINUTE"
+	CalcFormatDayHourMinute = "DAY_HOUR_MINUTE"
+)
+
+// Decimal は計算結果を数値として返す
+func (f CalcField) Decimal() (Decimal, error) {
+	return ParseDecimal(string(f))
+}
+
+// Duration は表示形式が HOUR_MINUTE, DAY_HOUR_MINUTE の計算結果（秒数）を time.Duration で返す
+func (f CalcField) Duration() (time.Duration, error) {
+	d, err := f.Decimal()
+	if err != nil {
+		return 0, err
+	}
+	r := d.Rat()
+	r.Mul(r, big.NewRat(int64(time.Second), 1))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("invalid duration: %s", string(f))
+	}
+	return time.Duration(r.Num().Int64()), nil
+}
+
+// Parse は計算フィールドの表示形式（FormField.Format）に従って値を変換する
+// NUMBER, NUMBER_DIGIT, HOUR_MINUTE, DAY_HOUR_MINUTE は DecimalField、
+// DATETIME は DateTimeField、DATE は DateField、TIME は TimeField を返す
+func (f CalcField) Parse(format string) (Field, error) {
+	data, err := json.Marshal(string(f))
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "", CalcFormatNumber, CalcFormatNumberDigit, CalcFormatHourMinute, CalcFormatDayHourMinute:
+		d, err := f.Decimal()
+		if err != nil {
+			return nil, err
+		}
+		return DecimalField{d}, nil
+	case CalcFormatDateTime:
+		var _f DateTimeField
+		err = json.Unmarshal(data, &_f)
+		return _f, err
+	case CalcFormatDate:
+		var _f DateField
+		err = json.Unmarshal(data, &_f)
+		return _f, err
+	case CalcFormatTime:
+		return TimeField(f), nil
+	default:
+		return nil, fmt.Errorf("unknown calc format: %s", format)
+	}
+}
+
+//-CalcField
+
 //+TextsField
 
 // CheckBoxField ...
@@ -174,6 +555,11 @@ func (f CheckBoxField) String() string {
 	return strings.Join([]string(f), ",")
 }
 
+// IsNull ...
+func (f CheckBoxField) IsNull() bool {
+	return len(f) == 0
+}
+
 // MultiSelectField ...
 type MultiSelectField []string
 
@@ -184,6 +570,11 @@ func (f MultiSelectField) String() string {
 	return strings.Join([]string(f), ",")
 }
 
+// IsNull ...
+func (f MultiSelectField) IsNull() bool {
+	return len(f) == 0
+}
+
 // CategoryField ...
 type CategoryField []string
 
@@ -194,66 +585,57 @@ func (f CategoryField) String() string {
 	return strings.Join([]string(f), ",")
 }
 
+// IsNull ...
+func (f CategoryField) IsNull() bool {
+	return len(f) == 0
+}
+
 //-TextsField
 
 //+DateField
 
 // DateField ...
+// 日付はタイムゾーンを持たない Date で保持する。Value がゼロ値の場合は null として扱う
 type DateField struct {
-	Value interface{} // nil or time.Time
+	Value Date
 }
 
 // NewDateField ...
 func NewDateField(year, month, day int) *DateField {
-	t := DateField{time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)}
+	t := DateField{NewDate(year, time.Month(month), day)}
 	return &t
 }
 
+// Time は UTC の 0 時を返す
 func (f DateField) Time() (time.Time, error) {
-	var t time.Time
-	if f.Value == nil {
-		return t, fmt.Errorf("value is nil")
-	}
-	if t, ok := f.Value.(time.Time); ok {
-		return t, nil
-	}
-	return t, fmt.Errorf("value is invalid")
+	return f.TimeIn(time.UTC)
 }
 
-// UnmarshalJSON ...
-func (f *DateField) UnmarshalJSON(data []byte) error {
-	var v string
-	err := json.Unmarshal(data, &v)
-	if err != nil {
-		return err
+// TimeIn は loc での 0 時を返す
+func (f DateField) TimeIn(loc *time.Location) (time.Time, error) {
+	if f.IsNull() {
+		return time.Time{}, fmt.Errorf("value is nil")
 	}
+	return f.Value.In(loc), nil
+}
 
-	if v == "" {
-		*f = DateField{}
-		return nil
-	}
+// IsNull ...
+func (f DateField) IsNull() bool {
+	return f.Value.IsZero()
+}
 
-	t, err := time.Parse("2006-01-02", v)
-	if err != nil {
-		return err
-	}
-	*f = DateField{t}
-	return nil
+// UnmarshalJSON ...
+func (f *DateField) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &f.Value)
 }
 
 // MarshalJSON ...
 func (f DateField) MarshalJSON() ([]byte, error) {
-	if f.Value != nil {
-		return json.Marshal(f.String())
-	}
 	return json.Marshal(f.Value)
 }
 
 func (f DateField) String() string {
-	if v, ok := f.Value.(time.Time); ok {
-		return v.Format("2006-01-02")
-	}
-	return ""
+	return f.Value.String()
 }
 
 //-DateField
@@ -261,21 +643,55 @@ func (f DateField) String() string {
 //+DateTimeField
 
 // DateTimeField ...
+// 送信時は常にオフセット付きの RFC3339 形式にする。Value がゼロ値の場合は null として扱う
+// 作成日時・更新日時も DateTimeField としてデコードし、フィールドタイプを保持する
 type DateTimeField struct {
-	Value interface{} // nil or time.Time
+	Value time.Time
+
+	fieldType string // 作成日時・更新日時の場合のフィールドタイプ
 }
 
+// NewDateTimeField は UTC の日時を返す
 func NewDateTimeField(year, month, day, hour, min int) *DateTimeField {
-	t := DateTimeField{time.Date(year, time.Month(month), day, hour, min, 0, 0, time.UTC)}
+	return NewDateTimeFieldIn(year, month, day, hour, min, time.UTC)
+}
+
+// NewDateTimeFieldIn は loc での日時を返す
+func NewDateTimeFieldIn(year, month, day, hour, min int, loc *time.Location) *DateTimeField {
+	if loc == nil {
+		loc = time.Local
+	}
+	t := DateTimeField{Value: time.Date(year, time.Month(month), day, hour, min, 0, 0, loc)}
 	return &t
 }
 
 func (f DateTimeField) String() string {
-	if v, ok := f.Value.(time.Time); ok {
-		return v.Format("2006-01-02 15:04:05")
-		// return v.Format(time.RFC3339)
+	if f.IsNull() {
+		return ""
 	}
-	return ""
+	return f.Value.Format(time.RFC3339)
+}
+
+// In は日時を loc に変換した値を返す
+func (f DateTimeField) In(loc *time.Location) DateTimeField {
+	if f.IsNull() || loc == nil {
+		return f
+	}
+	f.Value = f.Value.In(loc)
+	return f
+}
+
+// Date は loc での日付を返す
+func (f DateTimeField) Date(loc *time.Location) Date {
+	if f.IsNull() {
+		return Date{}
+	}
+	return DateOf(f.In(loc).Value)
+}
+
+// IsNull ...
+func (f DateTimeField) IsNull() bool {
+	return f.Value.IsZero()
 }
 
 // UnmarshalJSON ...
@@ -296,30 +712,69 @@ func (f *DateTimeField) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	*f = DateTimeField{t}
+	*f = DateTimeField{Value: t}
 	return nil
 }
 
 // MarshalJSON ...
 func (f DateTimeField) MarshalJSON() ([]byte, error) {
-	if f.Value != nil {
-		return json.Marshal(f.String())
+	if f.IsNull() {
+		return []byte("null"), nil
 	}
-	return json.Marshal(f.Value)
+	return json.Marshal(f.String())
 }
 
+// CreatedTimeField は作成日時フィールド。FieldTypeOf が CREATED_TIME を返す DateTimeField としてデコードする
+type CreatedTimeField = DateTimeField
+
+// UpdatedTimeField は更新日時フィールド。FieldTypeOf が UPDATED_TIME を返す DateTimeField としてデコードする
+type UpdatedTimeField = DateTimeField
+
 //-DateTimeField
 
 //+TimeField
 
-// TimeField ...
+// TimeField は "15:04" 形式の時刻
+// タイムゾーンを持たない壁時計の時刻として扱う
 type TimeField string
 
-func newTimeField(hour, min int) TimeField {
+// NewTimeField ...
+func NewTimeField(hour, min int) TimeField {
 	t := time.Date(1, time.January, 1, hour, min, 0, 0, time.UTC)
 	return TimeField(t.Format("15:04"))
 }
 
+// TimeFieldOf は t のロケーションでの時刻を返す
+func TimeFieldOf(t time.Time) TimeField {
+	return NewTimeField(t.Hour(), t.Minute())
+}
+
+// Clock は時と分を返す
+func (f TimeField) Clock() (hour, min int, err error) {
+	t, err := time.Parse("15:04", string(f))
+	if err != nil {
+		return 0, 0, err
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// On は日付 d の loc での時刻を返す
+func (f TimeField) On(d Date, loc *time.Location) (time.Time, error) {
+	hour, min, err := f.Clock()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return time.Date(d.Year, d.Month, d.Day, hour, min, 0, 0, loc), nil
+}
+
+// IsNull ...
+func (f TimeField) IsNull() bool {
+	return f == ""
+}
+
 //-TimeField
 
 //+FileField
@@ -345,6 +800,11 @@ func (f FileField) String() string {
 	return strings.Join(args, ",")
 }
 
+// IsNull ...
+func (f FileField) IsNull() bool {
+	return len(f) == 0
+}
+
 // File ...
 type File struct {
 	ContentType string `json:"contentType"`
@@ -372,12 +832,48 @@ func (c *CodeField) String() string {
 }
 
 // UserField ...
-type UserField CodeField
+// 作成者・更新者も *UserField としてデコードし、フィールドタイプを保持する
+type UserField struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+
+	fieldType string // 作成者・更新者の場合のフィールドタイプ
+}
 
 func (f *UserField) String() string {
 	return f.Name
 }
 
+// IsNull は作成者・更新者の値が空かどうかを返す
+func (f *UserField) IsNull() bool {
+	return f == nil || f.Code == ""
+}
+
+// CreatorField は作成者フィールド。FieldTypeOf が CREATOR を返す *UserField としてデコードする
+// レコードの登録時のみ値を指定できる
+type CreatorField = UserField
+
+// ModifierField は更新者フィールド。FieldTypeOf が MODIFIER を返す *UserField としてデコードする
+// レコードの登録時のみ値を指定できる
+type ModifierField = UserField
+
+// AssigneeField はプロセス管理の作業者
+// 値の変更はステータスの更新で行うため、レコードの登録・更新時には送信しない
+type AssigneeField []*UserField
+
+func (f AssigneeField) String() string {
+	var args []string
+	for _, u := range f {
+		args = append(args, u.String())
+	}
+	return strings.Join(args, ",")
+}
+
+// IsNull ...
+func (f AssigneeField) IsNull() bool {
+	return len(f) == 0
+}
+
 // OrganizationField ...
 type OrganizationField CodeField
 
@@ -403,6 +899,11 @@ func (f *TableField) String() string {
 	return ""
 }
 
+// IsNull ...
+func (f TableField) IsNull() bool {
+	return len(f) == 0
+}
+
 // MarshalJSON ...
 func (f TableField) MarshalJSON() ([]byte, error) {
 	type Raw struct {
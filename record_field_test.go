@@ -45,7 +45,8 @@ func TestDateTimeField(t *testing.T) {
 		Input    []byte
 		Expected string
 	}{
-		{[]byte(`"2014-02-16T08:57:00Z"`), "2014-02-16 08:57:00"},
+		{[]byte(`"2014-02-16T08:57:00Z"`), "2014-02-16T08:57:00Z"},
+		{[]byte(`"2014-02-16T17:57:00+09:00"`), "2014-02-16T17:57:00+09:00"},
 		{[]byte(`""`), ""},
 	}
 
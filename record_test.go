@@ -3,6 +3,8 @@ package kintone
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 	"testing"
 )
 
@@ -269,6 +271,9 @@ func TestUnmarshalRecord(t *testing.T) {
 					"name":"佐藤　昇"
 				}
 			},
+			"レコード番号": {
+				"value": "1"
+			},
 			"ドロップダウン": {
 				"value": "sample2"
 			},
@@ -394,7 +399,7 @@ func TestDateFieldMarshal(t *testing.T) {
 	//-valueに値が入っている場合
 
 	//+valueがnullの場合
-	f = &DateField{nil}
+	f = &DateField{}
 	data, err = json.Marshal(f)
 	if err != nil {
 		t.Error(err)
@@ -425,10 +430,309 @@ func TestDateFieldUnMarshal(t *testing.T) {
 		return
 	}
 
-	if v, ok := record.Fields["日付"].(DateField); ok {
-		if v.Value == nil {
-			t.Log("nil!")
+	if v, ok := record.Fields["日付"].(DateField); !ok || !v.IsNull() {
+		t.Errorf("unexpected 日付: %#v", record.Fields["日付"])
+	}
+}
+
+func TestNullFieldUnmarshal(t *testing.T) {
+	data := []byte(`{
+		"text": {"type": "SINGLE_LINE_TEXT", "value": null},
+		"number": {"type": "NUMBER", "value": ""},
+		"number_null": {"type": "NUMBER", "value": null},
+		"select": {"type": "DROP_DOWN", "value": null},
+		"radio": {"type": "RADIO_BUTTON", "value": null},
+		"check": {"type": "CHECK_BOX", "value": []},
+		"date": {"type": "DATE", "value": null},
+		"datetime": {"type": "DATETIME", "value": ""},
+		"time": {"type": "TIME", "value": null},
+		"users": {"type": "USER_SELECT", "value": []},
+		"files": {"type": "FILE", "value": null},
+		"table": {"type": "SUBTABLE", "value": null},
+		"zero": {"type": "NUMBER", "value": "0"}
+	}`)
+
+	var record Record
+	err := json.Unmarshal(data, &record)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for code, f := range record.Fields {
+		if code == "zero" {
+			continue
+		}
+		if !IsNull(f) {
+			t.Errorf("%s should be null: %#v", code, f)
+		}
+	}
+
+	if IsNull(record.Fields["zero"]) {
+		t.Errorf("zero should not be null: %#v", record.Fields["zero"])
+	}
+
+	// 空の数値は NullNumberField
+	for _, code := range []string{"number", "number_null"} {
+		if f, ok := record.Fields[code].(NullNumberField); !ok || !IsNull(f) || FieldTypeOf(f) != FieldTypeNumber {
+			t.Errorf("unexpected %s: %#v", code, record.Fields[code])
+		}
+	}
+	data, err = json.Marshal(record.Fields["number"])
+	if err != nil || string(data) != `""` {
+		t.Errorf("unexpected number: %s, %v", data, err)
+	}
+
+	// int64 の値は全て NumberField
+	f, err := decodeNumber([]byte(`"-9223372036854775808"`))
+	if err != nil || f != NumberField(math.MinInt64) {
+		t.Errorf("unexpected min int64: %#v, %v", f, err)
+	}
+
+	// 空の値を NumberField にデコードした場合は 0
+	var n NumberField
+	if err := json.Unmarshal([]byte(`""`), &n); err != nil || n != 0 {
+		t.Errorf("unexpected number: %d, %v", n, err)
+	}
+}
+
+func TestNullFieldMarshal(t *testing.T) {
+	fs := Fields{
+		"number": NewNullField(FieldTypeNumber),
+		"select": NewNullField(FieldTypeSingleSelect),
+		"date":   NewNullField(FieldTypeDate),
+		"time":   NewNullField(FieldTypeTime),
+		"users":  NewNullField(FieldTypeUsers),
+	}
+
+	actual, err := json.Marshal(fs)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []byte(`{
+		"number": {"value": ""},
+		"select": {"value": null},
+		"date": {"value": null},
+		"time": {"value": ""},
+		"users": {"value": []}
+	}`)
+
+	if !jsonEqual(expected, actual) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(actual))
+	}
+}
+
+func TestUnmarshalRecordNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"レコード番号": {"type": "RECORD_NUMBER", "value": "12"}}`, "12"},
+		{`{"レコード番号": {"type": "RECORD_NUMBER", "value": "SALES-12"}}`, "12"},
+		{`{"レコード番号": {"type": "RECORD_NUMBER", "value": "SALES-12"}, "$id": {"type": "__ID__", "value": "3"}}`, "3"},
+	}
+
+	for _, test := range tests {
+		var r Record
+		err := json.Unmarshal([]byte(test.input), &r)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if r.ID != test.expected {
+			t.Errorf("expected: %s, actual: %s", test.expected, r.ID)
 		}
+		if _, ok := r.Fields["レコード番号"].(RecordNumberField); !ok {
+			t.Errorf("unexpected レコード番号: %#v", r.Fields["レコード番号"])
+		}
+	}
+}
+
+type testPointField struct {
+	X, Y float64
+}
+
+func (f testPointField) FieldType() string {
+	return "POINT"
+}
+
+func TestRegisterFieldType(t *testing.T) {
+	RegisterFieldType("POINT", func(data json.RawMessage) (Field, error) {
+		var f testPointField
+		err := json.Unmarshal(data, &f)
+		return f, err
+	})
+
+	data := []byte(`{
+		"point": {"type": "POINT", "value": {"X": 1, "Y": 2}},
+		"future": {"type": "FUTURE_TYPE", "value": {"a": [1, 2]}}
+	}`)
+
+	var r Record
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if p, ok := r.Fields["point"].(testPointField); !ok || p.Y != 2 {
+		t.Errorf("unexpected point: %#v", r.Fields["point"])
+	}
+	if FieldTypeOf(r.Fields["point"]) != "POINT" {
+		t.Errorf("unexpected type: %s", FieldTypeOf(r.Fields["point"]))
+	}
+
+	u, ok := r.Fields["future"].(UnknownField)
+	if !ok || FieldTypeOf(u) != "FUTURE_TYPE" {
+		t.Errorf("unexpected future: %#v", r.Fields["future"])
+		return
+	}
+
+	actual, err := json.Marshal(Fields{"future": u})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected := []byte(`{"future": {"value": {"a": [1, 2]}}}`)
+	if !jsonEqual(expected, actual) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(actual))
+	}
+}
+
+func TestWriteFields(t *testing.T) {
+	data := []byte(`{
+		"$id": {"type": "__ID__", "value": "1"},
+		"$revision": {"type": "__REVISION__", "value": "2"},
+		"レコード番号": {"type": "RECORD_NUMBER", "value": "1"},
+		"計算": {"type": "CALC", "value": "3"},
+		"ステータス": {"type": "STATUS", "value": "未処理"},
+		"作業者": {"type": "STATUS_ASSIGNEE", "value": [{"code": "sato", "name": "佐藤"}]},
+		"作成者": {"type": "CREATOR", "value": {"code": "sato", "name": "佐藤"}},
+		"作成日時": {"type": "CREATED_TIME", "value": "2014-02-16T08:59:00Z"},
+		"ユーザー": {"type": "USER_SELECT", "value": [{"code": "sato", "name": "佐藤"}]},
+		"組織": {"type": "ORGANIZATION_SELECT", "value": [{"code": "dev", "name": "開発"}]},
+		"グループ": {"type": "GROUP_SELECT", "value": [{"code": "admin", "name": "管理者"}]},
+		"テーブル": {
+			"type": "SUBTABLE",
+			"value": [
+				{"id": "10", "value": {"担当": {"type": "USER_SELECT", "value": [{"code": "sato", "name": "佐藤"}]}}}
+			]
+		}
+	}`)
+
+	var r Record
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, ok := r.Fields["作業者"].(AssigneeField); !ok {
+		t.Errorf("unexpected 作業者: %#v", r.Fields["作業者"])
+	}
+	if _, ok := r.Fields["作成者"].(*UserField); !ok {
+		t.Errorf("unexpected 作成者: %#v", r.Fields["作成者"])
+	}
+	if _, ok := r.Fields["作成日時"].(DateTimeField); !ok {
+		t.Errorf("unexpected 作成日時: %#v", r.Fields["作成日時"])
+	}
+	if FieldTypeOf(r.Fields["作成者"]) != FieldTypeCreator || FieldTypeOf(r.Fields["作成日時"]) != FieldTypeCreatedDateTime {
+		t.Errorf("field types should be kept: %s %s", FieldTypeOf(r.Fields["作成者"]), FieldTypeOf(r.Fields["作成日時"]))
+	}
+
+	actual, err := json.Marshal(writeFields(r.Fields))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []byte(`{
+		"作成者": {"value": {"code": "sato"}},
+		"作成日時": {"value": "2014-02-16T08:59:00Z"},
+		"ユーザー": {"value": [{"code": "sato"}]},
+		"組織": {"value": [{"code": "dev"}]},
+		"グループ": {"value": [{"code": "admin"}]},
+		"テーブル": {"value": [{"id": "10", "value": {"担当": {"value": [{"code": "sato"}]}}}]}
+	}`)
+
+	if !jsonEqual(expected, actual) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(actual))
+	}
+}
+
+func TestRecordMarshalJSON(t *testing.T) {
+	data := []byte(`{
+		"$id": {"type": "__ID__", "value": "1"},
+		"$revision": {"type": "__REVISION__", "value": "7"},
+		"レコード番号": {"type": "RECORD_NUMBER", "value": "SALES-1"},
+		"文字列": {"type": "SINGLE_LINE_TEXT", "value": "テスト"},
+		"数値": {"type": "NUMBER", "value": "1.50"},
+		"空の数値": {"type": "NUMBER", "value": ""},
+		"日付": {"type": "DATE", "value": null},
+		"日時": {"type": "DATETIME", "value": "2014-02-16T08:57:00Z"},
+		"作成日時": {"type": "CREATED_TIME", "value": "2014-02-16T08:59:00Z"},
+		"更新日時": {"type": "UPDATED_TIME", "value": "2014-02-17T02:35:00Z"},
+		"作成者": {"type": "CREATOR", "value": {"code": "sato", "name": "佐藤"}},
+		"更新者": {"type": "MODIFIER", "value": {"code": "suzuki", "name": "鈴木"}},
+		"作業者": {"type": "STATUS_ASSIGNEE", "value": []},
+		"未知": {"type": "FUTURE_TYPE", "value": {"a":1}},
+		"テーブル": {
+			"type": "SUBTABLE",
+			"value": [
+				{"id": "33347", "value": {"数量": {"type": "NUMBER", "value": "2"}}}
+			]
+		}
+	}`)
+
+	var r Record
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	actual, err := json.Marshal(r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !jsonEqual(data, actual) {
+		t.Errorf("expected: %s, actual: %s", string(data), string(actual))
+	}
+
+	var r2 Record
+	err = json.Unmarshal(actual, &r2)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if r2.ID != r.ID || !reflect.DeepEqual(r.Fields, r2.Fields) {
+		t.Errorf("expected: %#v, actual: %#v", r, r2)
+	}
+}
+
+func TestRecordMarshalJSONWithoutID(t *testing.T) {
+	r := NewRecord("5", Fields{"文字列": SingleLineTextField("a")})
+
+	actual, err := json.Marshal(r)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expected := []byte(`{
+		"$id": {"type": "__ID__", "value": "5"},
+		"文字列": {"type": "SINGLE_LINE_TEXT", "value": "a"}
+	}`)
+	if !jsonEqual(expected, actual) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(actual))
+	}
+
+	_, err = json.Marshal(NewRecord("", Fields{"x": struct{}{}}))
+	if err == nil {
+		t.Error("expected error")
 	}
-	// t.Log(record)
 }
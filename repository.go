@@ -1,11 +1,10 @@
 package kintone
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -24,12 +23,26 @@ type Repository struct {
 	Client   Client
 	Token    chan struct{}
 	MaxRetry int
+
+	// Location は読み込んだ日時フィールドの値を変換するロケーション
+	// nil の場合は kintone から受け取った UTC のまま
+	Location *time.Location
+
+	// Validate が true の場合、レコードの登録・更新前にフォームの設定で検証する
+	// 検証エラーがある場合はリクエストを送信せず *ValidationError を返す
+	Validate bool
+
+	// ApplyDefaults が true の場合、登録するレコードに含まれないフィールドにフォームの初期値を設定する
+	ApplyDefaults bool
 }
 
 type RepositoryOption struct {
 	HTTPClient    *http.Client
 	MaxConcurrent int
 	MaxRetry      int
+	Location      *time.Location
+	Validate      bool
+	ApplyDefaults bool
 }
 
 type Cursor struct {
@@ -40,6 +53,8 @@ type Cursor struct {
 // NewRepository ...
 func NewRepository(subdomain string, username, password string, option *RepositoryOption) *Repository {
 	var httpClient *http.Client
+	var loc *time.Location
+	var validate, applyDefaults bool
 	maxConcurrent := 1
 	maxRetry := 3
 
@@ -55,13 +70,17 @@ func NewRepository(subdomain string, username, password string, option *Reposito
 		if option.MaxRetry != 0 {
 			maxRetry = option.MaxRetry
 		}
+
+		loc = option.Location
+		validate = option.Validate
+		applyDefaults = option.ApplyDefaults
 	}
 
 	c := newClient(subdomain, username, password, httpClient)
 	token := make(chan struct{}, maxConcurrent)
 	u, _ := url.ParseRequestURI(fmt.Sprintf(APIEndpointBase, subdomain))
 	c.endpointBase = u
-	return &Repository{c, token, maxRetry}
+	return &Repository{c, token, maxRetry, loc, validate, applyDefaults}
 }
 
 // ReadRecords ...
@@ -140,6 +159,8 @@ func (repo *Repository) readRecords(ctx context.Context, q *Query) ([]*Record, e
 		return nil, err
 	}
 
+	localizeRecords(r.Records, repo.Location)
+
 	return r.Records, nil
 }
 
@@ -162,6 +183,42 @@ func (repo *Repository) readTotalCount(q *Query) (int, error) {
 	return r.TotalCount, nil
 }
 
+// ReadRecord はレコード ID を指定してレコードを1件取得する
+func (repo *Repository) ReadRecord(ctx context.Context, appID int, id string) (*Record, error) {
+	if appID == 0 {
+		return nil, errors.New("appID is required")
+	}
+
+	_id, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid record id: %s", id)
+	}
+
+	body, err := repo.Client.get(APIEndpointRecord, &Query{AppID: appID, ID: _id})
+	if err != nil {
+		return nil, err
+	}
+
+	var r struct {
+		Record *Record `json:"record"`
+	}
+
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, err
+	}
+	if r.Record == nil {
+		return nil, ErrNotFound
+	}
+
+	localizeRecords([]*Record{r.Record}, repo.Location)
+
+	if r.Record.ID == "" {
+		r.Record.ID = id
+	}
+
+	return r.Record, nil
+}
+
 func (repo *Repository) ReadRecordsWithCursor(q *Query) ([]*Record, error) {
 	c, err := repo.getCursor(q)
 	if err != nil {
@@ -195,6 +252,7 @@ func (repo *Repository) ReadRecordsWithCursor(q *Query) ([]*Record, error) {
 			return nil, err
 		}
 
+		localizeRecords(response.Records, repo.Location)
 		rs = append(rs, response.Records...)
 
 		if !response.Next {
@@ -239,12 +297,47 @@ func (repo *Repository) getCursor(q *Query) (*Cursor, error) {
 
 //+AddRecord
 
+// prepareRecords は ApplyDefaults と Validate の設定に従い、送信前のレコードを処理する
+// 初期値は登録時のみ設定し、呼び出し元のレコードは変更しない
+func (repo *Repository) prepareRecords(appID int, rs []*Record, update bool) ([]*Record, error) {
+	applyDefaults := repo.ApplyDefaults && !update
+	if (!repo.Validate && !applyDefaults) || len(rs) == 0 {
+		return rs, nil
+	}
+
+	form, err := repo.ReadFormFields(appID)
+	if err != nil {
+		return nil, errors.Wrap(err, "read form fields failed")
+	}
+
+	if applyDefaults {
+		loc := repo.Location
+		if loc == nil {
+			loc = time.Local
+		}
+		rs = withDefaults(form, rs, time.Now().In(loc))
+	}
+
+	if repo.Validate {
+		if err := ValidateRecords(form, rs, update); err != nil {
+			return nil, err
+		}
+	}
+
+	return rs, nil
+}
+
 // AddRecords ...
 func (repo *Repository) AddRecords(ctx context.Context, appID int, rs ...*Record) ([]string, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
+	rs, err := repo.prepareRecords(appID, rs, false)
+	if err != nil {
+		return nil, err
+	}
+
 	sliced := sliceRecords(rs, 100)
 
 	var ids []string
@@ -264,7 +357,7 @@ func (repo *Repository) AddRecords(ctx context.Context, appID int, rs ...*Record
 		})
 	}
 
-	err := eg.Wait()
+	err = eg.Wait()
 	if err != nil {
 		return nil, err
 	}
@@ -273,12 +366,18 @@ func (repo *Repository) AddRecords(ctx context.Context, appID int, rs ...*Record
 }
 
 func (repo *Repository) AddRecord(ctx context.Context, appID int, r *Record) (string, error) {
+	rs, err := repo.prepareRecords(appID, []*Record{r}, false)
+	if err != nil {
+		return "", err
+	}
+	r = rs[0]
+
 	type requestBody struct {
-		App    int    `json:"app"`
-		Record Fields `json:"record"`
+		App    int         `json:"app"`
+		Record writeFields `json:"record"`
 	}
 
-	body, err := json.Marshal(requestBody{appID, r.Fields})
+	body, err := json.Marshal(requestBody{appID, writeFields(r.Fields)})
 	if err != nil {
 		return "", err
 	}
@@ -315,13 +414,13 @@ func (repo *Repository) addRecords(ctx context.Context, appID int, rs []*Record)
 	}
 
 	type requestBody struct {
-		App     int      `json:"app"`
-		Records []Fields `json:"records"`
+		App     int           `json:"app"`
+		Records []writeFields `json:"records"`
 	}
 
-	fs := make([]Fields, len(rs))
+	fs := make([]writeFields, len(rs))
 	for i, r := range rs {
-		fs[i] = r.Fields
+		fs[i] = writeFields(r.Fields)
 	}
 
 	body, err := json.Marshal(requestBody{appID, fs})
@@ -361,13 +460,13 @@ func (repo *Repository) addRecordsWithRetry(ctx context.Context, appID int, rs [
 	}
 
 	type requestBody struct {
-		App     int      `json:"app"`
-		Records []Fields `json:"records"`
+		App     int           `json:"app"`
+		Records []writeFields `json:"records"`
 	}
 
-	fs := make([]Fields, len(rs))
+	fs := make([]writeFields, len(rs))
 	for i, r := range rs {
-		fs[i] = r.Fields
+		fs[i] = writeFields(r.Fields)
 	}
 
 	var retryCount int
@@ -421,12 +520,16 @@ func (repo *Repository) UpdateRecord(ctx context.Context, appID int, updateKey s
 		return nil
 	}
 
+	if _, err := repo.prepareRecords(appID, []*Record{r}, true); err != nil {
+		return err
+	}
+
 	type RequestBody interface{}
 
 	type RequestBodyWithRecordID struct {
-		App    int    `json:"app"`
-		ID     string `json:"id"`
-		Fields Fields `json:"record"`
+		App    int         `json:"app"`
+		ID     string      `json:"id"`
+		Fields writeFields `json:"record"`
 	}
 
 	type UpdateKey struct {
@@ -435,19 +538,19 @@ func (repo *Repository) UpdateRecord(ctx context.Context, appID int, updateKey s
 	}
 
 	type RequestBodyWithUpdateKey struct {
-		App       int        `json:"app"`
-		UpdateKey *UpdateKey `json:"updateKey"`
-		Fields    Fields     `json:"record"`
+		App       int         `json:"app"`
+		UpdateKey *UpdateKey  `json:"updateKey"`
+		Fields    writeFields `json:"record"`
 	}
 
 	var requestBody RequestBody
 
 	if updateKey == "" {
-		requestBody = &RequestBodyWithRecordID{App: appID, ID: r.ID, Fields: r.Fields}
+		requestBody = &RequestBodyWithRecordID{App: appID, ID: r.ID, Fields: writeFields(r.Fields)}
 	} else {
 		u := UpdateKey{Field: updateKey, Value: fmt.Sprint(r.Fields[updateKey])}
 		delete(r.Fields, updateKey)
-		requestBody = &RequestBodyWithUpdateKey{App: appID, UpdateKey: &u, Fields: r.Fields}
+		requestBody = &RequestBodyWithUpdateKey{App: appID, UpdateKey: &u, Fields: writeFields(r.Fields)}
 	}
 
 	body, err := json.Marshal(requestBody)
@@ -473,6 +576,11 @@ func (repo *Repository) UpdateRecords(ctx context.Context, appID int, updateKey
 		ctx = context.Background()
 	}
 
+	_, err := repo.prepareRecords(appID, rs, true)
+	if err != nil {
+		return err
+	}
+
 	sliced := sliceRecords(rs, 100)
 
 	eg, ctx := errgroup.WithContext(ctx)
@@ -514,8 +622,8 @@ func (repo *Repository) updateRecords(ctx context.Context, appID int, rs []*Reco
 	type UpdateRecord interface{}
 
 	type UpdateRecordWithID struct {
-		ID     string `json:"id"`
-		Record Fields `json:"record"`
+		ID     string      `json:"id"`
+		Record writeFields `json:"record"`
 	}
 
 	type UpdateKey struct {
@@ -524,8 +632,8 @@ func (repo *Repository) updateRecords(ctx context.Context, appID int, rs []*Reco
 	}
 
 	type UpdateRecordWithUpdateKey struct {
-		UpdateKey UpdateKey `json:"updateKey"`
-		Record    Fields    `json:"record"`
+		UpdateKey UpdateKey   `json:"updateKey"`
+		Record    writeFields `json:"record"`
 	}
 
 	type requestBody struct {
@@ -537,11 +645,11 @@ func (repo *Repository) updateRecords(ctx context.Context, appID int, rs []*Reco
 
 	for i, r := range rs {
 		if updateKey == "" {
-			records[i] = &UpdateRecordWithID{r.ID, r.Fields}
+			records[i] = &UpdateRecordWithID{r.ID, writeFields(r.Fields)}
 		} else {
 			u := UpdateKey{Field: updateKey, Value: fmt.Sprint(r.Fields[updateKey])}
 			delete(r.Fields, updateKey)
-			records[i] = &UpdateRecordWithUpdateKey{u, r.Fields}
+			records[i] = &UpdateRecordWithUpdateKey{u, writeFields(r.Fields)}
 		}
 	}
 
@@ -580,8 +688,8 @@ func (repo *Repository) updateRecordsWithRetry(ctx context.Context, appID int, r
 	type UpdateRecord interface{}
 
 	type UpdateRecordWithID struct {
-		ID     string `json:"id"`
-		Record Fields `json:"record"`
+		ID     string      `json:"id"`
+		Record writeFields `json:"record"`
 	}
 
 	type UpdateKey struct {
@@ -590,8 +698,8 @@ func (repo *Repository) updateRecordsWithRetry(ctx context.Context, appID int, r
 	}
 
 	type UpdateRecordWithUpdateKey struct {
-		UpdateKey UpdateKey `json:"updateKey"`
-		Record    Fields    `json:"record"`
+		UpdateKey UpdateKey   `json:"updateKey"`
+		Record    writeFields `json:"record"`
 	}
 
 	type requestBody struct {
@@ -603,11 +711,11 @@ func (repo *Repository) updateRecordsWithRetry(ctx context.Context, appID int, r
 
 	for i, r := range rs {
 		if updateKey == "" {
-			records[i] = &UpdateRecordWithID{r.ID, r.Fields}
+			records[i] = &UpdateRecordWithID{r.ID, writeFields(r.Fields)}
 		} else {
 			u := UpdateKey{Field: updateKey, Value: fmt.Sprint(r.Fields[updateKey])}
 			delete(r.Fields, updateKey)
-			records[i] = &UpdateRecordWithUpdateKey{u, r.Fields}
+			records[i] = &UpdateRecordWithUpdateKey{u, writeFields(r.Fields)}
 		}
 	}
 
@@ -880,6 +988,46 @@ func (repo *Repository) upsertRecords(ctx context.Context, appID int, updateKey
 
 //-UpsertRecords
 
+//+Struct
+
+// ReadRecordsInto はレコードを取得し、構造体のスライス v（*[]T or *[]*T）に格納する
+func (repo *Repository) ReadRecordsInto(ctx context.Context, q *Query, v interface{}) error {
+	rs, err := repo.ReadRecords(ctx, q)
+	if err != nil {
+		return err
+	}
+	return UnmarshalRecords(rs, v)
+}
+
+// AddRecordsFrom は構造体のスライス v をレコードとして追加する
+func (repo *Repository) AddRecordsFrom(ctx context.Context, appID int, v interface{}) ([]string, error) {
+	rs, err := MarshalRecords(v)
+	if err != nil {
+		return nil, err
+	}
+	return repo.AddRecords(ctx, appID, rs...)
+}
+
+// UpdateRecordsFrom は構造体のスライス v でレコードを更新する
+func (repo *Repository) UpdateRecordsFrom(ctx context.Context, appID int, updateKey string, v interface{}) error {
+	rs, err := MarshalRecords(v)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateRecords(ctx, appID, updateKey, rs...)
+}
+
+// UpsertRecordsFrom は構造体のスライス v でレコードを追加・更新する
+func (repo *Repository) UpsertRecordsFrom(ctx context.Context, appID int, updateKey string, v interface{}) error {
+	rs, err := MarshalRecords(v)
+	if err != nil {
+		return err
+	}
+	return repo.UpsertRecords(ctx, appID, updateKey, rs...)
+}
+
+//-Struct
+
 // ReadFormFields ...
 func (repo *Repository) ReadFormFields(appID int) (FormFields, error) {
 	data, err := repo.Client.get(APIEndpointFormField, &Query{AppID: appID})
@@ -890,18 +1038,130 @@ func (repo *Repository) ReadFormFields(appID int) (FormFields, error) {
 		Properties FormFields `json:"properties"`
 	}{}
 
-	var buf bytes.Buffer
-	err = json.Indent(&buf, data, "", "  ")
+	err = json.Unmarshal(data, &raw)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	log.Println(buf.String())
+	return raw.Properties, nil
+}
+
+// ReadPreviewFormFields は運用環境に反映する前のフォームのフィールドと revision を返す
+func (repo *Repository) ReadPreviewFormFields(appID int) (FormFields, string, error) {
+	data, err := repo.Client.get(APIEndpointPreviewFormField, &Query{AppID: appID})
+	if err != nil {
+		return nil, "", err
+	}
+	raw := struct {
+		Properties FormFields `json:"properties"`
+		Revision   string     `json:"revision"`
+	}{}
 
 	err = json.Unmarshal(data, &raw)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return raw.Properties, nil
+	return raw.Properties, raw.Revision, nil
+}
+
+// AddFormFields はフォームにフィールドを追加し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) AddFormFields(appID int, fs FormFields, revision string) (string, error) {
+	return repo.writeFormFields(repo.Client.post, appID, fs, revision)
+}
+
+// UpdateFormFields はフォームのフィールドの設定を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateFormFields(appID int, fs FormFields, revision string) (string, error) {
+	return repo.writeFormFields(repo.Client.put, appID, fs, revision)
+}
+
+func (repo *Repository) writeFormFields(send func(string, []byte) ([]byte, error), appID int, fs FormFields, revision string) (string, error) {
+	body, err := json.Marshal(struct {
+		App        int        `json:"app"`
+		Properties FormFields `json:"properties"`
+		Revision   string     `json:"revision,omitempty"`
+	}{appID, fs, revision})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := send(APIEndpointPreviewFormField, body)
+	if err != nil {
+		return "", err
+	}
+	return unmarshalRevision(data)
+}
+
+// DeleteFormFields はフォームのフィールドを削除し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) DeleteFormFields(appID int, codes []string, revision string) (string, error) {
+	body, err := json.Marshal(struct {
+		App      int      `json:"app"`
+		Fields   []string `json:"fields"`
+		Revision string   `json:"revision,omitempty"`
+	}{appID, codes, revision})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := repo.Client.delete(APIEndpointPreviewFormField, body)
+	if err != nil {
+		return "", err
+	}
+	return unmarshalRevision(data)
+}
+
+func unmarshalRevision(data []byte) (string, error) {
+	raw := struct {
+		Revision string `json:"revision"`
+	}{}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return "", err
+	}
+	return raw.Revision, nil
+}
+
+// readAppSetting はアプリの設定を v に読み込む
+func (repo *Repository) readAppSetting(path string, appID int, v interface{}) error {
+	data, err := repo.Client.get(path, &Query{AppID: appID})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// updateAppSetting は v にアプリ ID と revision を加えて送信し、変更後の revision を返す
+// v は JSON のオブジェクトに変換できる値
+func (repo *Repository) updateAppSetting(path string, appID int, v interface{}, revision string) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	var body map[string]interface{}
+	err = json.Unmarshal(data, &body)
+	if err != nil {
+		return "", err
+	}
+	body["app"] = appID
+	if revision != "" {
+		body["revision"] = revision
+	}
+
+	data, err = json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+
+	data, err = repo.Client.put(path, data)
+	if err != nil {
+		return "", err
+	}
+	return unmarshalRevision(data)
 }
 
 // ReadFormLayout ...
@@ -1030,10 +1290,24 @@ func (q Query) String() string {
 //+kintone error
 
 type resError struct {
-	Code    string      `json:"code"`
-	ID      string      `json:"id"`
-	Message string      `json:"message"`
-	Details interface{} `json:"errors"`
+	HTTPStatusCode int         `json:"-"`
+	Code           string      `json:"code"`
+	ID             string      `json:"id"`
+	Message        string      `json:"message"`
+	Details        interface{} `json:"errors"`
+}
+
+// isRetryable は再試行してよいエラーかどうかを返す
+// 通信エラー（net.Error）と 5xx、429 のレスポンスのみ再試行し、GAIA_* などの 4xx やキャンセルは再試行しない
+func isRetryable(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case *resError:
+		return e.HTTPStatusCode == http.StatusTooManyRequests || e.HTTPStatusCode >= 500
+	case net.Error:
+		// *url.Error も含む
+		return true
+	}
+	return false
 }
 
 func (e *resError) Error() string {
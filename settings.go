@@ -0,0 +1,38 @@
+package kintone
+
+// AppSettings はアプリの一般設定
+type AppSettings struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Icon        *AppIcon `json:"icon,omitempty"`
+	Theme       string   `json:"theme,omitempty"` // WHITE, RED, BLUE, GREEN, YELLOW, BLACK
+}
+
+// AppIcon はアプリのアイコン
+// Type が FILE の場合の Key はドメイン固有のため、他のアプリには設定できない
+type AppIcon struct {
+	Type string `json:"type"` // PRESET or FILE
+	Key  string `json:"key,omitempty"`
+}
+
+// ReadAppSettings はアプリの一般設定を返す
+func (repo *Repository) ReadAppSettings(appID int) (*AppSettings, error) {
+	var s AppSettings
+	err := repo.readAppSetting(APIEndpointAppSettings, appID, &s)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UpdateAppSettings はアプリの一般設定を変更し、変更後の revision を返す
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateAppSettings(appID int, s *AppSettings, revision string) (string, error) {
+	_s := *s
+	if _s.Icon != nil && _s.Icon.Type != "PRESET" {
+		// ファイルのアイコンは変更しない
+		_s.Icon = nil
+	}
+	return repo.updateAppSetting(APIEndpointPreviewAppSettings, appID, &_s, revision)
+}
@@ -1,24 +1,40 @@
 package kintone
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
 
 type Space struct {
-	ID           int    `json:"id,string"`
-	Name         string `json:"name"`
-	IsPrivate    bool   `json:"isPrivate"`
-	MemberCount  int    `json:"memberCount,string"`
-	Body         string `json:"body"`
-	AttachedApps []*App `json:"attachedApps"`
+	ID             int        `json:"id,string"`
+	Name           string     `json:"name"`
+	DefaultThread  int        `json:"defaultThread,string"`
+	IsPrivate      bool       `json:"isPrivate"`
+	Creator        *UserField `json:"creator"`
+	Modifier       *UserField `json:"modifier"`
+	MemberCount    int        `json:"memberCount"`
+	CoverType      string     `json:"coverType"` // BUILTIN or FILE
+	CoverKey       string     `json:"coverKey"`
+	CoverURL       string     `json:"coverUrl"`
+	Body           string     `json:"body"`
+	UseMultiThread bool       `json:"useMultiThread"`
+	IsGuest        bool       `json:"isGuest"`
+	AttachedApps   []*App     `json:"attachedApps"`
 }
 
 type App struct {
 	AppID       int            `json:"appID,string"`
+	Code        string         `json:"code"`
 	Name        string         `json:"name"`
 	Description string         `json:"description"`
 	CreatedAt   *DateTimeField `json:"createdAt"`
 	Creator     *Entity        `json:"creator"`
 	Modifier    *Entity        `json:"modifier"`
 	UpdatedAt   *DateTimeField `json:"modifiedAt"`
+	SpaceID     int            `json:"spaceId,string"`  // スペースに所属しない場合は 0
+	ThreadID    int            `json:"threadId,string"` // スペースに所属しない場合は 0
 }
 
 type CreateSpace struct {
@@ -29,24 +45,194 @@ type CreateSpace struct {
 }
 
 type CreateSpaceMember struct {
-	EntityType string
+	EntityType string // USER, ORGANIZATION, GROUP
 	Code       string
 	IsAdmin    bool
+	// 組織の場合に下位組織のユーザーも含めるかどうか
+	IncludeSubs bool
 }
 
 func (r *CreateSpaceMember) MarshalJSON() ([]byte, error) {
-	type Entity struct {
-		EntityType string `json:"type"`
-		Code       string `json:"code"`
+	return json.Marshal(&SpaceMember{
+		Entity:      Entity{Code: r.Code, Type: r.EntityType},
+		IsAdmin:     r.IsAdmin,
+		IncludeSubs: r.IncludeSubs,
+	})
+}
+
+// SpaceMember はスペースのメンバー
+type SpaceMember struct {
+	Entity      Entity `json:"entity"` // USER, ORGANIZATION, GROUP
+	IsAdmin     bool   `json:"isAdmin"`
+	IsImplicit  bool   `json:"isImplicit,omitempty"` // 組織やグループのメンバーとして参加しているユーザー。更新時は無視される
+	IncludeSubs bool   `json:"includeSubs"`          // 組織の場合に下位組織のユーザーも含めるかどうか
+}
+
+// ThreadComment はスレッドのコメント
+type ThreadComment struct {
+	Text     string        `json:"text,omitempty"`
+	Mentions []*Entity     `json:"mentions,omitempty"`
+	Files    []*ThreadFile `json:"files,omitempty"`
+}
+
+// ThreadFile はスレッドのコメントの添付ファイル
+// FileKey には UploadFile で取得したファイルキーを指定する
+type ThreadFile struct {
+	FileKey string `json:"fileKey"`
+	Width   int    `json:"width,omitempty"` // 画像の表示幅。0 の場合は元の大きさ
+}
+
+// UpdateSpaceBody はスペースの本文を HTML で更新する
+func (repo *Repository) UpdateSpaceBody(spaceID int, body string) error {
+	data, err := json.Marshal(struct {
+		ID   int    `json:"id"`
+		Body string `json:"body"`
+	}{spaceID, body})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Client.put(APIEndpointSpaceBody, data)
+	return err
+}
+
+// DeleteSpace はスペースを削除する
+func (repo *Repository) DeleteSpace(spaceID int) error {
+	data, err := json.Marshal(struct {
+		ID int `json:"id"`
+	}{spaceID})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Client.delete(APIEndpointSpace, data)
+	return err
+}
+
+// ReadSpaceMembers はスペースのメンバーを返す
+func (repo *Repository) ReadSpaceMembers(spaceID int) ([]*SpaceMember, error) {
+	data, err := repo.Client.get(APIEndpointSpaceMembers, &Query{ID: spaceID})
+	if err != nil {
+		return nil, err
 	}
 
 	raw := struct {
-		Entity  *Entity `json:"entity"`
-		IsAdmin bool    `json:"isAdmin"`
-	}{
-		Entity:  &Entity{r.EntityType, r.Code},
-		IsAdmin: true,
+		Members []*SpaceMember `json:"members"`
+	}{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return raw.Members, nil
+}
+
+// UpdateSpaceMembers はスペースのメンバーを ms に置き換える
+// ms に含まれないメンバーはスペースから外れる。管理者は 1 人以上必要
+func (repo *Repository) UpdateSpaceMembers(spaceID int, ms []*SpaceMember) error {
+	// 組織やグループ経由のメンバーは指定できない
+	members := make([]*SpaceMember, 0, len(ms))
+	for _, m := range ms {
+		if !m.IsImplicit {
+			members = append(members, m)
+		}
+	}
+
+	data, err := json.Marshal(struct {
+		ID      int            `json:"id"`
+		Members []*SpaceMember `json:"members"`
+	}{spaceID, members})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Client.put(APIEndpointSpaceMembers, data)
+	return err
+}
+
+// AddSpaceGuests はゲストスペースにゲストを追加する
+// codes にはゲストのメールアドレスを指定する
+func (repo *Repository) AddSpaceGuests(spaceID int, codes []string) error {
+	data, err := json.Marshal(struct {
+		ID     int      `json:"id"`
+		Guests []string `json:"guests"`
+	}{spaceID, codes})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Client.put(fmt.Sprintf(APIEndpointGuestSpaceGuests, spaceID), data)
+	return err
+}
+
+// AddThread はスペースにスレッドを作成し、スレッド ID を返す
+// スペースがシングルスレッドの場合は作成できない
+func (repo *Repository) AddThread(spaceID int, name string) (int, error) {
+	data, err := json.Marshal(struct {
+		Space int    `json:"space"`
+		Name  string `json:"name"`
+	}{spaceID, name})
+	if err != nil {
+		return 0, err
+	}
+
+	data, err = repo.Client.post(APIEndpointSpaceThread, data)
+	if err != nil {
+		return 0, err
 	}
 
-	return json.Marshal(&raw)
+	res := struct {
+		ID int `json:"id,string"`
+	}{}
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return 0, err
+	}
+	return res.ID, nil
+}
+
+// UpdateThread はスレッドの名前と本文を更新する
+// 空の値は変更しない
+func (repo *Repository) UpdateThread(threadID int, name, body string) error {
+	data, err := json.Marshal(struct {
+		ID   int    `json:"id"`
+		Name string `json:"name,omitempty"`
+		Body string `json:"body,omitempty"`
+	}{threadID, name, body})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.Client.put(APIEndpointSpaceThread, data)
+	return err
+}
+
+// AddThreadComment はスレッドにコメントを書き込み、コメント ID を返す
+// Text か Files のどちらかが必要
+func (repo *Repository) AddThreadComment(spaceID, threadID int, c *ThreadComment) (int, error) {
+	if c.Text == "" && len(c.Files) == 0 {
+		return 0, errors.New("text or files is required")
+	}
+
+	data, err := json.Marshal(struct {
+		Space   int            `json:"space"`
+		Thread  int            `json:"thread"`
+		Comment *ThreadComment `json:"comment"`
+	}{spaceID, threadID, c})
+	if err != nil {
+		return 0, err
+	}
+
+	data, err = repo.Client.post(APIEndpointSpaceThreadComment, data)
+	if err != nil {
+		return 0, err
+	}
+
+	res := struct {
+		ID int `json:"id,string"`
+	}{}
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return 0, err
+	}
+	return res.ID, nil
 }
@@ -91,7 +91,9 @@ func TestSpace(t *testing.T) {
 		return
 	}
 
-	t.Log(f)
+	if f.ID != 1 || f.DefaultThread != 3 || f.MemberCount != 10 || f.Creator.Code != "tanaka" || len(f.AttachedApps) != 3 {
+		t.Errorf("unexpected space: %#v", f)
+	}
 }
 
 func TestUnmarshalCreateSpace(t *testing.T) {
@@ -104,6 +106,11 @@ func TestUnmarshalCreateSpace(t *testing.T) {
 				Code:       "mycode",
 				IsAdmin:    true,
 			},
+			&CreateSpaceMember{
+				EntityType:  EntityTypeOrganization,
+				Code:        "sales",
+				IncludeSubs: true,
+			},
 		},
 	}
 	data, err := json.Marshal(&s)
@@ -113,10 +120,115 @@ func TestUnmarshalCreateSpace(t *testing.T) {
 	}
 
 	actual := string(data)
-	expected := `{"id":1,"name":"test","members":[{"entity":{"type":"mycode","value":"mycode"},"isAdmin":true}]}`
+	expected := `{"id":1,"name":"test","members":[` +
+		`{"entity":{"code":"mycode","type":"USER"},"isAdmin":true,"includeSubs":false},` +
+		`{"entity":{"code":"sales","type":"ORGANIZATION"},"isAdmin":false,"includeSubs":true}` +
+		`],"isPrivate":false}`
 
 	if actual != expected {
 		t.Errorf("expected: %s, actual: %s", expected, actual)
 		return
 	}
 }
+
+func TestSpaceMembers(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"members": [
+			{"entity": {"type": "USER", "code": "sato"}, "isAdmin": true, "isImplicit": false},
+			{"entity": {"type": "ORGANIZATION", "code": "sales"}, "isAdmin": false, "includeSubs": true},
+			{"entity": {"type": "USER", "code": "suzuki"}, "isAdmin": false, "isImplicit": true}
+		]}`), nil
+	})
+
+	ms, err := repo.ReadSpaceMembers(1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(ms) != 3 || !ms[0].IsAdmin || ms[1].IsAdmin || !ms[1].IncludeSubs || !ms[2].IsImplicit {
+		t.Errorf("unexpected members: %v", ms)
+	}
+	if req := c.requests[0]; req.Path != APIEndpointSpaceMembers || req.Query.ID != 1 {
+		t.Errorf("unexpected request: %#v", req)
+	}
+
+	if err := repo.UpdateSpaceMembers(1, ms); err != nil {
+		t.Error(err)
+		return
+	}
+	expected := []byte(`{"id": 1, "members": [
+		{"entity": {"type": "USER", "code": "sato"}, "isAdmin": true, "includeSubs": false},
+		{"entity": {"type": "ORGANIZATION", "code": "sales"}, "isAdmin": false, "includeSubs": true}
+	]}`)
+	if req := c.requests[1]; req.Method != "PUT" || !jsonEqual(expected, req.Body) {
+		t.Errorf("expected: %s, actual: %s", expected, req.Body)
+	}
+}
+
+func TestSpaceThread(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"id": "5"}`), nil
+	})
+
+	id, err := repo.AddThread(1, "連絡")
+	if err != nil || id != 5 {
+		t.Errorf("unexpected thread: %d, %v", id, err)
+		return
+	}
+	if err := repo.UpdateThread(5, "", "<b>本文</b>"); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := repo.AddThreadComment(1, 5, &ThreadComment{}); err == nil {
+		t.Error("expected error for empty comment")
+	}
+	id, err = repo.AddThreadComment(1, 5, &ThreadComment{
+		Text:     "確認してください",
+		Mentions: []*Entity{{Code: "sales", Type: EntityTypeOrganization}},
+		Files:    []*ThreadFile{{FileKey: "key1", Width: 250}},
+	})
+	if err != nil || id != 5 {
+		t.Errorf("unexpected comment: %d, %v", id, err)
+		return
+	}
+
+	expected := [][]byte{
+		[]byte(`{"space": 1, "name": "連絡"}`),
+		[]byte(`{"id": 5, "body": "<b>本文</b>"}`),
+		[]byte(`{"space": 1, "thread": 5, "comment": {
+			"text": "確認してください",
+			"mentions": [{"code": "sales", "type": "ORGANIZATION"}],
+			"files": [{"fileKey": "key1", "width": 250}]
+		}}`),
+	}
+	paths := []string{APIEndpointSpaceThread, APIEndpointSpaceThread, APIEndpointSpaceThreadComment}
+	for i, req := range c.requests {
+		if req.Path != paths[i] || !jsonEqual(expected[i], req.Body) {
+			t.Errorf("expected: %s, actual: %s", expected[i], req.Body)
+		}
+	}
+}
+
+func TestDeleteSpace(t *testing.T) {
+	repo, c := newFakeRepository(nil)
+
+	if err := repo.UpdateSpaceBody(1, "<p>本文</p>"); err != nil {
+		t.Error(err)
+	}
+	if err := repo.AddSpaceGuests(2, []string{"guest@example.com"}); err != nil {
+		t.Error(err)
+	}
+	if err := repo.DeleteSpace(1); err != nil {
+		t.Error(err)
+	}
+
+	if req := c.requests[0]; req.Path != APIEndpointSpaceBody || !jsonEqual([]byte(`{"id": 1, "body": "<p>本文</p>"}`), req.Body) {
+		t.Errorf("unexpected request: %s %s", req.Path, req.Body)
+	}
+	if req := c.requests[1]; req.Path != "/k/guest/2/v1/space/guests.json" || !jsonEqual([]byte(`{"id": 2, "guests": ["guest@example.com"]}`), req.Body) {
+		t.Errorf("unexpected request: %s %s", req.Path, req.Body)
+	}
+	if req := c.requests[2]; req.Method != "DELETE" || req.Path != APIEndpointSpace || !jsonEqual([]byte(`{"id": 1}`), req.Body) {
+		t.Errorf("unexpected request: %s %s", req.Path, req.Body)
+	}
+}
@@ -0,0 +1,207 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// errCodeConflict は revision が一致しない場合のエラーコード
+const errCodeConflict = "GAIA_CO02"
+
+// TableChange はサブテーブルの行に対する変更
+// 現在の行を受け取り、変更後の行を返す
+type TableChange func(rows TableField) (TableField, error)
+
+// AppendRows は行を末尾に追加する
+func AppendRows(rows ...*Record) TableChange {
+	return func(current TableField) (TableField, error) {
+		for _, r := range rows {
+			// 新しい行として追加するため ID は送信しない
+			current = append(current, &Record{Fields: r.Fields})
+		}
+		return current, nil
+	}
+}
+
+// UpdateRow は行 ID が一致する行のフィールドを fs の値で上書きする
+func UpdateRow(id string, fs Fields) TableChange {
+	return func(current TableField) (TableField, error) {
+		for _, r := range current {
+			if r.ID == id {
+				mergeFields(r, fs)
+				return current, nil
+			}
+		}
+		return nil, errors.Wrapf(ErrNotFound, "row %s", id)
+	}
+}
+
+// UpdateRowsByKey は key のフィールドの値が一致する行を rows の値で上書きする
+// 一致する行が無い場合はエラーを返す
+func UpdateRowsByKey(key string, rows ...*Record) TableChange {
+	return func(current TableField) (TableField, error) {
+		for _, r := range rows {
+			row, err := findRow(current, key, r.Fields[key])
+			if err != nil {
+				return nil, err
+			}
+			mergeFields(row, r.Fields)
+		}
+		return current, nil
+	}
+}
+
+// UpsertRowsByKey は key のフィールドの値が一致する行を上書きし、一致する行が無ければ追加する
+func UpsertRowsByKey(key string, rows ...*Record) TableChange {
+	return func(current TableField) (TableField, error) {
+		for _, r := range rows {
+			row, err := findRow(current, key, r.Fields[key])
+			if errors.Cause(err) == ErrNotFound {
+				current = append(current, &Record{Fields: r.Fields})
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			mergeFields(row, r.Fields)
+		}
+		return current, nil
+	}
+}
+
+// RemoveRows は行 ID が一致する行を削除する
+func RemoveRows(ids ...string) TableChange {
+	return func(current TableField) (TableField, error) {
+		remove := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			remove[id] = true
+		}
+
+		rows := make(TableField, 0, len(current))
+		for _, r := range current {
+			if !remove[r.ID] {
+				rows = append(rows, r)
+			}
+		}
+		return rows, nil
+	}
+}
+
+// RemoveRowsWhere は f が true を返す行を削除する
+func RemoveRowsWhere(f func(row *Record) bool) TableChange {
+	return func(current TableField) (TableField, error) {
+		rows := make(TableField, 0, len(current))
+		for _, r := range current {
+			if !f(r) {
+				rows = append(rows, r)
+			}
+		}
+		return rows, nil
+	}
+}
+
+func findRow(rows TableField, key string, value Field) (*Record, error) {
+	if value == nil {
+		return nil, fmt.Errorf("key %s is required", key)
+	}
+
+	var found *Record
+	for _, r := range rows {
+		if !FieldEqual(r.Fields[key], value) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("key %s=%v matches multiple rows", key, value)
+		}
+		found = r
+	}
+	if found == nil {
+		return nil, errors.Wrapf(ErrNotFound, "row %s=%v", key, value)
+	}
+	return found, nil
+}
+
+func mergeFields(r *Record, fs Fields) {
+	if r.Fields == nil {
+		r.Fields = make(Fields)
+	}
+	for code, f := range fs {
+		r.Fields[code] = f
+	}
+}
+
+// UpdateTable はレコードを読み込み、サブテーブル tableCode に変更を適用して更新する
+// サブテーブルは省略した行が削除されるため、既存の行は行 ID とともに全て送信する
+// 読み込んだ revision を指定して更新し、他の更新と競合した場合は読み込みからやり直す（MaxRetry 回まで）
+// 更新後の行を返す（追加した行の ID は空）
+func (repo *Repository) UpdateTable(ctx context.Context, appID int, recordID, tableCode string, changes ...TableChange) (TableField, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for retry := 0; ; retry++ {
+		r, err := repo.ReadRecord(ctx, appID, recordID)
+		if err != nil {
+			return nil, errors.Wrap(err, "read record failed")
+		}
+
+		rows, err := r.Fields.Table(tableCode)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range changes {
+			rows, err = change(rows)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var revision string
+		if v, ok := r.Fields[fieldCodeRevision].(RevisionField); ok {
+			revision = string(v)
+		}
+
+		err = repo.updateRecordWithRevision(ctx, appID, recordID, Fields{tableCode: rows}, revision)
+		if isConflict(err) && retry < repo.MaxRetry {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return rows, nil
+	}
+}
+
+// updateRecordWithRevision は revision を指定してレコードを更新する
+// revision が空の場合は指定しない
+func (repo *Repository) updateRecordWithRevision(ctx context.Context, appID int, id string, fs Fields, revision string) error {
+	type requestBody struct {
+		App      int         `json:"app"`
+		ID       string      `json:"id"`
+		Record   writeFields `json:"record"`
+		Revision string      `json:"revision,omitempty"`
+	}
+
+	body, err := json.Marshal(requestBody{appID, id, writeFields(fs), revision})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return errors.New("canceled")
+	default:
+	}
+
+	_, err = repo.Client.put(APIEndpointRecord, body)
+	return err
+}
+
+func isConflict(err error) bool {
+	e, ok := errors.Cause(err).(*resError)
+	return ok && e.Code == errCodeConflict
+}
@@ -0,0 +1,115 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+const testTableRecord = `{
+	"record": {
+		"$id": {"type": "__ID__", "value": "1"},
+		"$revision": {"type": "__REVISION__", "value": "%s"},
+		"明細": {
+			"type": "SUBTABLE",
+			"value": [
+				{"id": "10", "value": {"商品": {"type": "SINGLE_LINE_TEXT", "value": "pen"}, "数量": {"type": "NUMBER", "value": "1"}}},
+				{"id": "11", "value": {"商品": {"type": "SINGLE_LINE_TEXT", "value": "note"}, "数量": {"type": "NUMBER", "value": "2"}}}
+			]
+		}
+	}
+}`
+
+func TestUpdateTable(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Method == "GET" {
+			return []byte(fmt.Sprintf(testTableRecord, "5")), nil
+		}
+		return []byte(`{"revision": "6"}`), nil
+	})
+
+	rows, err := repo.UpdateTable(context.Background(), 3, "1", "明細",
+		UpdateRowsByKey("商品", &Record{Fields: Fields{"商品": SingleLineTextField("pen"), "数量": NumberField(5)}}),
+		RemoveRows("11"),
+		AppendRows(&Record{ID: "99", Fields: Fields{"商品": SingleLineTextField("eraser")}}),
+	)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(rows) != 2 {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+
+	get := c.requests[0]
+	if get.Path != APIEndpointRecord || get.Query.AppID != 3 || get.Query.ID != 1 {
+		t.Errorf("unexpected request: %#v", get)
+	}
+
+	expected := []byte(`{
+		"app": 3,
+		"id": "1",
+		"revision": "5",
+		"record": {
+			"明細": {
+				"value": [
+					{"id": "10", "value": {"商品": {"value": "pen"}, "数量": {"value": "5"}}},
+					{"value": {"商品": {"value": "eraser"}}}
+				]
+			}
+		}
+	}`)
+	put := c.requests[1]
+	if put.Method != "PUT" || !jsonEqual(expected, put.Body) {
+		t.Errorf("expected: %s, actual: %s", string(expected), string(put.Body))
+	}
+}
+
+func TestUpdateTableConflict(t *testing.T) {
+	var puts int
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Method == "GET" {
+			return []byte(fmt.Sprintf(testTableRecord, fmt.Sprintf("%d", 5+puts))), nil
+		}
+		puts++
+		if puts == 1 {
+			return nil, &resError{Code: errCodeConflict}
+		}
+		return []byte(`{"revision": "7"}`), nil
+	})
+	repo.MaxRetry = 3
+
+	_, err := repo.UpdateTable(context.Background(), 3, "1", "明細", UpdateRow("10", Fields{"数量": NumberField(3)}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if len(c.requests) != 4 {
+		t.Errorf("unexpected requests: %d", len(c.requests))
+		return
+	}
+
+	var body struct {
+		Revision string `json:"revision"`
+	}
+	json.Unmarshal(c.requests[3].Body, &body)
+	if body.Revision != "6" {
+		t.Errorf("expected: 6, actual: %s", body.Revision)
+	}
+}
+
+func TestUpdateTableRowNotFound(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(fmt.Sprintf(testTableRecord, "5")), nil
+	})
+
+	_, err := repo.UpdateTable(context.Background(), 3, "1", "明細", UpdateRow("12", Fields{"数量": NumberField(3)}))
+	if err == nil {
+		t.Error("expected error")
+	}
+	if len(c.requests) != 1 {
+		t.Errorf("record should not be updated: %d", len(c.requests))
+	}
+}
@@ -1,9 +1,13 @@
 package kintone
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"io/ioutil"
 	"log"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/joho/godotenv"
@@ -33,3 +37,88 @@ func jsonEqual(expected, actual []byte) bool {
 
 	return reflect.DeepEqual(d1, d2)
 }
+
+// fakeClient はリクエストを記録し、handler の戻り値をレスポンスとして返す
+type fakeClient struct {
+	mu       sync.Mutex
+	requests []*fakeRequest
+	handler  func(req *fakeRequest) ([]byte, error)
+}
+
+type fakeRequest struct {
+	Method string
+	Path   string
+	Query  *Query
+	Body   []byte
+
+	// ファイルのアップロード・ダウンロード
+	FileName    string
+	ContentType string
+	FileKey     string
+}
+
+func newFakeRepository(handler func(req *fakeRequest) ([]byte, error)) (*Repository, *fakeClient) {
+	c := &fakeClient{handler: handler}
+	return &Repository{Client: c, Token: make(chan struct{}, 1)}, c
+}
+
+func (c *fakeClient) do(method, path string, q *Query, body []byte) ([]byte, error) {
+	req := &fakeRequest{Method: method, Path: path, Body: body}
+	if q != nil {
+		_q := *q
+		req.Query = &_q
+	}
+	return c.record(req)
+}
+
+func (c *fakeClient) record(req *fakeRequest) ([]byte, error) {
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+
+	if c.handler == nil {
+		return []byte(`{}`), nil
+	}
+	return c.handler(req)
+}
+
+func (c *fakeClient) get(path string, q *Query) ([]byte, error) {
+	return c.do("GET", path, q, nil)
+}
+
+func (c *fakeClient) getWithBody(path string, body []byte) ([]byte, error) {
+	return c.do("GET", path, nil, body)
+}
+
+func (c *fakeClient) post(path string, body []byte) ([]byte, error) {
+	return c.do("POST", path, nil, body)
+}
+
+func (c *fakeClient) put(path string, body []byte) ([]byte, error) {
+	return c.do("PUT", path, nil, body)
+}
+
+func (c *fakeClient) delete(path string, body []byte) ([]byte, error) {
+	return c.do("DELETE", path, nil, body)
+}
+
+// upload はファイルの内容を Body に記録する
+func (c *fakeClient) upload(ctx context.Context, name, contentType string, r io.Reader) ([]byte, error) {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return c.record(&fakeRequest{Method: "POST", Path: APIEndpointFile, Body: body, FileName: name, ContentType: contentType})
+}
+
+// download は handler の戻り値をファイルの内容として書き込む
+func (c *fakeClient) download(ctx context.Context, fileKey string, w io.Writer) error {
+	data, err := c.record(&fakeRequest{Method: "GET", Path: APIEndpointFile, FileKey: fileKey})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (c *fakeClient) SetBasicAuth(username, password string) {}
@@ -0,0 +1,316 @@
+package kintone
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 検証ルール
+const (
+	RuleUnknownField = "unknownField" // フォームに存在しないフィールド
+	RuleReadOnly     = "readOnly"     // 値を指定できないフィールド
+	RuleType         = "type"         // フィールドタイプと値の型が合わない
+	RuleRequired     = "required"
+	RuleMaxLength    = "maxLength"
+	RuleMinLength    = "minLength"
+	RuleMaxValue     = "maxValue"
+	RuleMinValue     = "minValue"
+	RuleOption       = "option" // 選択肢に無い値
+	RuleUnique       = "unique" // バッチ内で値が重複している
+)
+
+// Violation はフィールドの検証エラー
+type Violation struct {
+	Index    int    // 検証したレコードの位置
+	RecordID string // 更新の場合のレコード ID
+	Code     string // フィールドコード。サブテーブル内のフィールドは "テーブル[0].フィールド" の形式
+	Rule     string
+	Message  string
+}
+
+func (v *Violation) String() string {
+	return fmt.Sprintf("record[%d] %s: %s", v.Index, v.Code, v.Message)
+}
+
+// ValidationError はレコードの検証結果
+type ValidationError struct {
+	Violations []*Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, 3)
+	for i, v := range e.Violations {
+		if i == cap(msgs) {
+			msgs = append(msgs, fmt.Sprintf("and %d more", len(e.Violations)-i))
+			break
+		}
+		msgs = append(msgs, v.String())
+	}
+	return "kintone: validation failed: " + strings.Join(msgs, ", ")
+}
+
+// ByRecord はレコードの位置ごとに検証エラーを返す
+func (e *ValidationError) ByRecord() map[int][]*Violation {
+	m := make(map[int][]*Violation)
+	for _, v := range e.Violations {
+		m[v.Index] = append(m[v.Index], v)
+	}
+	return m
+}
+
+// ValidateRecords はレコードをフォームの設定で検証する
+// update が true の場合は更新として扱い、レコードに含まれないフィールドの必須チェックは行わない
+// 計算、ステータスなど値を指定できないフィールドに値がある場合も検証エラーにする
+// 検証エラーがある場合は *ValidationError を返す
+func ValidateRecords(form FormFields, rs []*Record, update bool) error {
+	v := &validator{form: form, update: update}
+	for i, r := range rs {
+		v.index = i
+		v.recordID = ""
+		if update {
+			v.recordID = r.ID
+		}
+		v.validateFields(form, r.Fields, "")
+	}
+	v.validateUnique(rs)
+
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{v.violations}
+}
+
+type validator struct {
+	form       FormFields
+	update     bool
+	index      int
+	recordID   string
+	violations []*Violation
+}
+
+func (v *validator) add(code, rule, format string, args ...interface{}) {
+	v.violations = append(v.violations, &Violation{
+		Index:    v.index,
+		RecordID: v.recordID,
+		Code:     code,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateFields(form FormFields, fs Fields, prefix string) {
+	codes := make([]string, 0, len(fs))
+	for code := range fs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		f := fs[code]
+		if code == fieldCodeID || code == fieldCodeRevision {
+			continue
+		}
+		if !isWritableField(f) && IsNull(f) {
+			// 空の作成者など、送信されない空の値
+			continue
+		}
+		if !isWritableField(f) {
+			// 計算、ステータスなどは送信されないため、指定した値は反映されない
+			v.add(prefix+code, RuleReadOnly, "%s cannot be written", fieldTypeName(f))
+			continue
+		}
+
+		ff, ok := form[code]
+		if !ok || ff == nil {
+			v.add(prefix+code, RuleUnknownField, "field does not exist in the form")
+			continue
+		}
+		v.validateField(ff, f, prefix+code)
+	}
+
+	if v.update {
+		return
+	}
+	for _, code := range sortedFormCodes(form) {
+		ff := form[code]
+		if !ff.Required {
+			continue
+		}
+		if _, ok := fs[code]; !ok && !hasDefaultValue(ff) {
+			v.add(prefix+code, RuleRequired, "required")
+		}
+	}
+}
+
+func sortedFormCodes(form FormFields) []string {
+	codes := make([]string, 0, len(form))
+	for code, ff := range form {
+		if ff != nil {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func (v *validator) validateField(ff *FormField, f Field, code string) {
+	if !isWritableFieldType(ff.Type, v.update) {
+		v.add(code, RuleReadOnly, "%s cannot be written", ff.Type)
+		return
+	}
+
+	if !fieldTypeMatches(ff.Type, f) {
+		v.add(code, RuleType, "%s cannot be used as %s", fieldTypeName(f), ff.Type)
+		return
+	}
+
+	if IsNull(f) {
+		if ff.Required {
+			v.add(code, RuleRequired, "required")
+		}
+		return
+	}
+
+	switch f := f.(type) {
+	case SingleLineTextField:
+		v.validateLength(ff, string(f), code)
+	case MultiLineTextField:
+		v.validateLength(ff, string(f), code)
+	case LinkField:
+		v.validateLength(ff, string(f), code)
+	case NumberField, NullNumberField, DecimalField:
+		d, _ := fieldDecimal(f)
+		if max, err := ParseDecimal(ff.MaxValue); err == nil && !max.IsNull() && d.Cmp(max) > 0 {
+			v.add(code, RuleMaxValue, "%s is greater than %s", d, max)
+		}
+		if min, err := ParseDecimal(ff.MinValue); err == nil && !min.IsNull() && d.Cmp(min) < 0 {
+			v.add(code, RuleMinValue, "%s is less than %s", d, min)
+		}
+	case RadioButtonField:
+		v.validateOptions(ff, []string{string(f)}, code)
+	case SingleSelectField:
+		v.validateOptions(ff, []string{f.Value}, code)
+	case CheckBoxField:
+		v.validateOptions(ff, f, code)
+	case MultiSelectField:
+		v.validateOptions(ff, f, code)
+	case TableField:
+		for i, row := range f {
+			v.validateFields(ff.Fields, row.Fields, fmt.Sprintf("%s[%d].", code, i))
+		}
+	}
+}
+
+func (v *validator) validateLength(ff *FormField, s, code string) {
+	n := utf8.RuneCountInString(s)
+	if max, err := strconv.Atoi(ff.MaxLength); err == nil && n > max {
+		v.add(code, RuleMaxLength, "length %d exceeds %d", n, max)
+	}
+	if min, err := strconv.Atoi(ff.MinLength); err == nil && n < min {
+		v.add(code, RuleMinLength, "length %d is less than %d", n, min)
+	}
+}
+
+func (v *validator) validateOptions(ff *FormField, values []string, code string) {
+	options := make(map[string]bool, len(ff.Options))
+	for _, o := range ff.Options {
+		options[o] = true
+	}
+	for _, s := range values {
+		if !options[s] {
+			v.add(code, RuleOption, "%q is not an option", s)
+		}
+	}
+}
+
+// validateUnique は重複禁止のフィールドの値がバッチ内で重複していないかを検証する
+func (v *validator) validateUnique(rs []*Record) {
+	for _, code := range sortedFormCodes(v.form) {
+		if !v.form[code].Unique {
+			continue
+		}
+		seen := make(map[string]int)
+		for i, r := range rs {
+			f, ok := r.Fields[code]
+			if !ok || IsNull(f) {
+				continue
+			}
+			s := fmt.Sprint(f)
+			key := uniqueKey(f)
+			if j, ok := seen[key]; ok {
+				v.index = i
+				v.recordID = ""
+				if v.update {
+					v.recordID = r.ID
+				}
+				v.add(code, RuleUnique, "%q is duplicated with record[%d]", s, j)
+				continue
+			}
+			seen[key] = i
+		}
+	}
+}
+
+// uniqueKey は重複を判定するための値を返す
+// 数値は "1.5" と "1.50" を、日時はタイムゾーンが異なる同じ日時を同じ値とする
+func uniqueKey(f Field) string {
+	switch f := f.(type) {
+	case NumberField, DecimalField:
+		if d, err := fieldDecimal(f); err == nil {
+			return d.Rat().RatString()
+		}
+	case DateField:
+		return f.String()
+	case DateTimeField:
+		return f.Value.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(f)
+}
+
+// hasDefaultValue は省略した場合に初期値が設定されるかどうかを返す
+func hasDefaultValue(ff *FormField) bool {
+	if ff.DefaultTime {
+		return true
+	}
+	switch v := ff.DefaultValue.(type) {
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+// isWritableFieldType はフィールドタイプに値を指定できるかどうかを返す
+// 作成者、更新者、作成日時、更新日時はレコードの登録時のみ指定できる
+func isWritableFieldType(fieldType string, update bool) bool {
+	switch fieldType {
+	case FieldTypeCalc, FieldTypeStatus, FieldTypeAssignee, FieldTypeCategory, FieldTypeRecordNumber:
+		return false
+	case FieldTypeCreator, FieldTypeModifier, FieldTypeCreatedDateTime, FieldTypeUpdatedDateTime:
+		return !update
+	}
+	return true
+}
+
+// fieldTypeMatches は値がフィールドタイプとして送信できるかどうかを返す
+func fieldTypeMatches(fieldType string, f Field) bool {
+	t := FieldTypeOf(f)
+	if t == fieldType {
+		return true
+	}
+	switch fieldType {
+	case FieldTypeCreatedDateTime, FieldTypeUpdatedDateTime:
+		return t == FieldTypeDateTime
+	case FieldTypeCreator, FieldTypeModifier:
+		_, ok := f.(*UserField)
+		return ok
+	}
+	// 未対応のタイプはそのまま送信する
+	_, ok := f.(UnknownField)
+	return ok
+}
@@ -0,0 +1,158 @@
+package kintone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testValidateForm = FormFields{
+	"名前":    {Code: "名前", Type: FieldTypeSingleLineText, Required: true, MaxLength: "5", Unique: true},
+	"メモ":    {Code: "メモ", Type: FieldTypeMultiLineText, MinLength: "2"},
+	"数量":    {Code: "数量", Type: FieldTypeNumber, MaxValue: "10", MinValue: "1"},
+	"番号":    {Code: "番号", Type: FieldTypeNumber, Unique: true},
+	"日時":    {Code: "日時", Type: FieldTypeDateTime, Unique: true},
+	"ランク":   {Code: "ランク", Type: FieldTypeSingleSelect, Options: Options{"A", "B"}},
+	"タグ":    {Code: "タグ", Type: FieldTypeCheckBox, Options: Options{"x", "y"}},
+	"区分":    {Code: "区分", Type: FieldTypeRadioButton, Required: true, DefaultValue: "通常"},
+	"計算":    {Code: "計算", Type: FieldTypeCalc},
+	"作成者":   {Code: "作成者", Type: FieldTypeCreator},
+	"ステータス": {Code: "ステータス", Type: FieldTypeStatus},
+	"明細": {Code: "明細", Type: FieldTypeSubtable, Fields: FormFields{
+		"商品": {Code: "商品", Type: FieldTypeSingleLineText, Required: true},
+	}},
+}
+
+func TestValidateRecords(t *testing.T) {
+	rs := []*Record{
+		{Fields: Fields{
+			"名前":  SingleLineTextField("佐藤"),
+			"数量":  NumberField(3),
+			"ランク": SingleSelectField{"A"},
+			"タグ":  CheckBoxField{"x"},
+			"作成者": &UserField{Code: "sato"},
+			"計算":  CalcField(""), // 空の計算は送信されないため検証しない
+			"明細":  TableField{{Fields: Fields{"商品": SingleLineTextField("pen")}}},
+		}},
+		{Fields: Fields{
+			"名前":    SingleLineTextField("佐藤"),
+			"メモ":    MultiLineTextField("a"),
+			"数量":    DecimalField{MustParseDecimal("10.5")},
+			"ランク":   SingleSelectField{"C"},
+			"タグ":    CheckBoxField{"x", "z"},
+			"ステータス": SingleLineTextField("完了"),
+			"計算":    CalcField("1"),
+			"存在しない": SingleLineTextField("a"),
+			"明細":    TableField{{Fields: Fields{}}},
+		}},
+		{Fields: Fields{
+			"数量": SingleLineTextField("1"),
+		}},
+	}
+
+	err := ValidateRecords(testValidateForm, rs, false)
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	type key struct {
+		Index int
+		Code  string
+		Rule  string
+	}
+	actual := make(map[key]bool)
+	for _, v := range ve.Violations {
+		actual[key{v.Index, v.Code, v.Rule}] = true
+	}
+
+	expected := []key{
+		{1, "メモ", RuleMinLength},
+		{1, "数量", RuleMaxValue},
+		{1, "ランク", RuleOption},
+		{1, "タグ", RuleOption},
+		{1, "ステータス", RuleReadOnly},
+		{1, "計算", RuleReadOnly},
+		{1, "存在しない", RuleUnknownField},
+		{1, "明細[0].商品", RuleRequired},
+		{1, "名前", RuleUnique},
+		{2, "数量", RuleType},
+		{2, "名前", RuleRequired},
+	}
+	for _, k := range expected {
+		if !actual[k] {
+			t.Errorf("%v is not reported: %v", k, err)
+		}
+	}
+	if len(ve.Violations) != len(expected) {
+		t.Errorf("unexpected violations: %v", err)
+	}
+	if len(ve.ByRecord()[0]) != 0 {
+		t.Errorf("record 0 should be valid: %v", ve.ByRecord()[0])
+	}
+
+	// 更新の場合は含まれないフィールドの必須チェックを行わず、作成者は指定できない
+	err = ValidateRecords(testValidateForm, []*Record{
+		{ID: "1", Fields: Fields{"数量": NumberField(2), "作成者": &UserField{Code: "sato"}}},
+	}, true)
+	if !errors.As(err, &ve) || len(ve.Violations) != 1 || ve.Violations[0].Rule != RuleReadOnly || ve.Violations[0].RecordID != "1" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateUnique(t *testing.T) {
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+	rs := []*Record{
+		{Fields: Fields{"名前": SingleLineTextField("a"), "番号": DecimalField{MustParseDecimal("1.5")}, "日時": DateTimeField{Value: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}}},
+		{Fields: Fields{"名前": SingleLineTextField("b"), "番号": DecimalField{MustParseDecimal("1.50")}, "日時": DateTimeField{Value: time.Date(2020, 1, 1, 9, 0, 0, 0, tokyo)}}},
+		{Fields: Fields{"名前": SingleLineTextField("c"), "番号": NumberField(10)}},
+		{Fields: Fields{"名前": SingleLineTextField("d"), "番号": DecimalField{MustParseDecimal("10.0")}}},
+		{Fields: Fields{"名前": SingleLineTextField("e"), "番号": NullNumberField{}}},
+		{Fields: Fields{"名前": SingleLineTextField("f"), "番号": NullNumberField{}}},
+	}
+
+	err := ValidateRecords(testValidateForm, rs, true)
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	var actual []string
+	for _, v := range ve.Violations {
+		if v.Rule == RuleUnique {
+			actual = append(actual, fmt.Sprintf("%d %s", v.Index, v.Code))
+		}
+	}
+	expected := []string{"1 日時", "1 番号", "3 番号"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v (%v)", expected, actual, err)
+	}
+}
+
+func TestRepositoryValidate(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		if req.Path == APIEndpointFormField {
+			return []byte(`{"properties": {"名前": {"type": "SINGLE_LINE_TEXT", "code": "名前", "required": true}}}`), nil
+		}
+		return []byte(`{"ids": ["1"], "revisions": ["1"]}`), nil
+	})
+	repo.Validate = true
+
+	_, err := repo.AddRecords(context.Background(), 1, &Record{Fields: Fields{}})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(c.requests) != 1 {
+		t.Errorf("records should not be sent: %d", len(c.requests))
+	}
+
+	_, err = repo.AddRecords(context.Background(), 1, &Record{Fields: Fields{"名前": SingleLineTextField("a")}})
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,100 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// 一覧の表示形式
+const (
+	ViewTypeList     = "LIST"
+	ViewTypeCalendar = "CALENDAR"
+	ViewTypeCustom   = "CUSTOM"
+)
+
+// Views は一覧名ごとの一覧の設定
+type Views map[string]*View
+
+// View は一覧の設定
+type View struct {
+	ID          string   `json:"id,omitempty"`
+	Type        string   `json:"type"` // LIST, CALENDAR, CUSTOM
+	Name        string   `json:"name"`
+	BuiltinType string   `json:"builtinType,omitempty"` // 「（作業者が自分）」の一覧は ASSIGNEE
+	Index       int      `json:"index,string"`
+	Fields      []string `json:"fields,omitempty"` // 表形式の一覧に表示するフィールド
+	Date        string   `json:"date,omitempty"`   // カレンダー形式の一覧の日付フィールド
+	Title       string   `json:"title,omitempty"`  // カレンダー形式の一覧のタイトルフィールド
+	HTML        string   `json:"html,omitempty"`   // カスタマイズ形式の一覧の HTML
+	Pager       *bool    `json:"pager,omitempty"`  // カスタマイズ形式の一覧でページネーションを表示するかどうか。nil の場合は指定しない
+	Device      string   `json:"device,omitempty"` // カスタマイズ形式の一覧を表示する画面（DESKTOP or ANY）
+	FilterCond  string   `json:"filterCond"`
+	Sort        string   `json:"sort"`
+}
+
+// ReadViews はアプリの一覧の設定を返す
+func (repo *Repository) ReadViews(appID int) (Views, error) {
+	raw := struct {
+		Views Views `json:"views"`
+	}{}
+	err := repo.readAppSetting(APIEndpointViews, appID, &raw)
+	if err != nil {
+		return nil, err
+	}
+	return raw.Views, nil
+}
+
+// ReadPreviewViews は運用環境に反映する前の一覧の設定と revision を返す
+func (repo *Repository) ReadPreviewViews(appID int) (Views, string, error) {
+	data, err := repo.Client.get(APIEndpointPreviewViews, &Query{AppID: appID})
+	if err != nil {
+		return nil, "", err
+	}
+	raw := struct {
+		Views    Views  `json:"views"`
+		Revision string `json:"revision"`
+	}{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw.Views, raw.Revision, nil
+}
+
+// Query は一覧の絞り込み条件とソートのクエリを返す
+// 表形式の一覧は一覧のフィールドとレコード ID のみ取得する
+func (v *View) Query(appID int) *Query {
+	q := &Query{AppID: appID, Condition: v.FilterCond, OrderBy: v.Sort}
+	if v.Type == ViewTypeList && len(v.Fields) > 0 {
+		q.Fields = append([]string{fieldCodeID}, v.Fields...)
+	}
+	return q
+}
+
+// ReadViewRecords は一覧名 name の一覧の条件でレコードを取得する
+// 一覧が無い場合は ErrNotFound を返す
+func (repo *Repository) ReadViewRecords(ctx context.Context, appID int, name string) ([]*Record, error) {
+	vs, err := repo.ReadViews(appID)
+	if err != nil {
+		return nil, errors.Wrap(err, "read views failed")
+	}
+
+	v, ok := vs[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrNotFound, "view %s", name)
+	}
+	return repo.ReadRecords(ctx, v.Query(appID))
+}
+
+// UpdateViews はアプリの一覧の設定を変更し、変更後の revision を返す
+// vs に含まれない一覧は削除される
+// revision が空の場合は revision を確認しない
+// 運用環境に反映するには DeployApps を呼ぶ
+func (repo *Repository) UpdateViews(appID int, vs Views, revision string) (string, error) {
+	raw := struct {
+		Views Views `json:"views"`
+	}{vs}
+	return repo.updateAppSetting(APIEndpointPreviewViews, appID, &raw, revision)
+}
@@ -0,0 +1,103 @@
+package kintone
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testViews = `{"views": {
+	"未完了": {"id": "1", "type": "LIST", "name": "未完了", "index": "0", "fields": ["名前", "期限"], "filterCond": "状態 not in (\"完了\")", "sort": "期限 asc"},
+	"予定": {"id": "2", "type": "CALENDAR", "name": "予定", "index": "1", "date": "期限", "title": "名前", "filterCond": "", "sort": "$id desc"},
+	"ボード": {"id": "3", "type": "CUSTOM", "name": "ボード", "index": "2", "html": "<div id=\"board\"></div>", "pager": true, "device": "ANY", "filterCond": "", "sort": "$id desc"}
+}, "revision": "5"}`
+
+func TestReadViews(t *testing.T) {
+	repo, _ := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(testViews), nil
+	})
+
+	vs, revision, err := repo.ReadPreviewViews(1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if revision != "5" || len(vs) != 3 {
+		t.Errorf("unexpected views: %s %v", revision, vs)
+	}
+	if v := vs["予定"]; v.Type != ViewTypeCalendar || v.Date != "期限" || v.Title != "名前" || v.Index != 1 {
+		t.Errorf("unexpected view: %#v", v)
+	}
+	if v := vs["ボード"]; v.Type != ViewTypeCustom || v.Pager == nil || !*v.Pager || v.Device != "ANY" {
+		t.Errorf("unexpected view: %#v", v)
+	}
+
+	q := vs["予定"].Query(1)
+	if q.Condition != "" || q.OrderBy != "$id desc" || q.Fields != nil {
+		t.Errorf("unexpected query: %#v", q)
+	}
+}
+
+func TestUpdateViewsPager(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		return []byte(`{"revision": "6"}`), nil
+	})
+
+	pager := false
+	vs := Views{
+		"ボード": {Type: ViewTypeCustom, Name: "ボード", HTML: "<div></div>", Pager: &pager},
+		"一覧":  {Type: ViewTypeList, Name: "一覧"},
+	}
+	if _, err := repo.UpdateViews(1, vs, ""); err != nil {
+		t.Error(err)
+		return
+	}
+
+	var body struct {
+		Views map[string]map[string]interface{} `json:"views"`
+	}
+	json.Unmarshal(c.requests[0].Body, &body)
+	if v, ok := body.Views["ボード"]["pager"]; !ok || v != false {
+		t.Errorf("pager should be sent: %s", c.requests[0].Body)
+	}
+	if _, ok := body.Views["一覧"]["pager"]; ok {
+		t.Errorf("pager should not be sent: %s", c.requests[0].Body)
+	}
+}
+
+func TestReadViewRecords(t *testing.T) {
+	repo, c := newFakeRepository(func(req *fakeRequest) ([]byte, error) {
+		switch {
+		case req.Path == APIEndpointViews:
+			return []byte(testViews), nil
+		case req.Query.limit == 0:
+			return []byte(`{"totalCount": "1"}`), nil
+		}
+		return []byte(`{"records": [{"$id": {"type": "__ID__", "value": "9"}, "名前": {"type": "SINGLE_LINE_TEXT", "value": "a"}}]}`), nil
+	})
+	repo.Token = make(chan struct{}, 1)
+
+	rs, err := repo.ReadViewRecords(context.Background(), 1, "未完了")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(rs) != 1 || rs[0].ID != "9" {
+		t.Errorf("unexpected records: %v", rs)
+	}
+
+	q := c.requests[len(c.requests)-1].Query
+	if q.Condition != `状態 not in ("完了")` || q.OrderBy != "期限 asc" {
+		t.Errorf("unexpected query: %#v", q)
+	}
+	if expected := []string{"$id", "名前", "期限"}; !reflect.DeepEqual(expected, q.Fields) {
+		t.Errorf("expected: %v, actual: %v", expected, q.Fields)
+	}
+
+	_, err = repo.ReadViewRecords(context.Background(), 1, "存在しない")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
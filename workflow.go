@@ -0,0 +1,248 @@
+package kintone
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Workflow はプロセス管理の設定から作る状態遷移のモデル
+// アクションを実行できるかどうかを API を呼ばずに確認する
+type Workflow struct {
+	Enable  bool
+	States  []*ProcessState // Index の順
+	Actions []*ProcessAction
+
+	filters map[*ProcessAction]*Filter
+}
+
+// NewWorkflow はプロセス管理の設定から Workflow を返す
+// アクションの条件が解析できない場合はエラーを返す
+func NewWorkflow(p *ProcessManagement) (*Workflow, error) {
+	w := &Workflow{
+		Enable:  p.Enable,
+		Actions: p.Actions,
+		filters: make(map[*ProcessAction]*Filter, len(p.Actions)),
+	}
+
+	for _, s := range p.States {
+		w.States = append(w.States, s)
+	}
+	sort.Slice(w.States, func(i, j int) bool {
+		return w.States[i].Index < w.States[j].Index
+	})
+
+	for _, a := range p.Actions {
+		f, err := ParseFilter(a.FilterCond)
+		if err != nil {
+			return nil, errors.Wrapf(err, "action %s", a.Name)
+		}
+		w.filters[a] = f
+	}
+
+	return w, nil
+}
+
+// State はステータス名のステータスを返す。無い場合は nil を返す
+func (w *Workflow) State(name string) *ProcessState {
+	for _, s := range w.States {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// InitialState はレコードの登録時のステータスを返す
+func (w *Workflow) InitialState() *ProcessState {
+	if len(w.States) == 0 {
+		return nil
+	}
+	return w.States[0]
+}
+
+// ActionsFrom はステータスから実行できるアクションを返す
+func (w *Workflow) ActionsFrom(status string) []*ProcessAction {
+	var as []*ProcessAction
+	for _, a := range w.Actions {
+		if a.From == status {
+			as = append(as, a)
+		}
+	}
+	return as
+}
+
+// Assignee はステータスの作業者の設定を返す
+func (w *Workflow) Assignee(status string) *ProcessAssignee {
+	if s := w.State(status); s != nil {
+		return s.Assignee
+	}
+	return nil
+}
+
+// TransitionError はアクションを実行できない理由
+type TransitionError struct {
+	RecordID string
+	Status   string
+	Action   string
+	Reason   string
+	Err      error // 条件を評価できなかった場合のエラー
+}
+
+func (e *TransitionError) Error() string {
+	msg := fmt.Sprintf("kintone: record %s cannot take action %q from %q: %s", e.RecordID, e.Action, e.Status, e.Reason)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Cause は条件を評価できなかった場合のエラーを返す
+func (e *TransitionError) Cause() error {
+	return e.Err
+}
+
+// TransitionErrors は CheckTransitions の結果
+type TransitionErrors []*TransitionError
+
+func (es TransitionErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// CanTake はレコードがアクションを実行できるかどうかを確認する
+// 実行できない場合は *TransitionError を返す
+// アクションの条件に TODAY() などの関数がある場合は Err が ErrUnsupportedFilter の *TransitionError を返す
+func (w *Workflow) CanTake(r *Record, action string) error {
+	status, _ := recordStatus(r)
+	return w.canTake(r, status, action)
+}
+
+func (w *Workflow) canTake(r *Record, status, action string) error {
+	e := &TransitionError{RecordID: r.ID, Status: status, Action: action}
+
+	if !w.Enable {
+		e.Reason = "process management is disabled"
+		return e
+	}
+	if status == "" {
+		e.Reason = "record has no status"
+		return e
+	}
+
+	a := w.action(status, action)
+	if a == nil {
+		e.Reason = "action is not available from the status"
+		return e
+	}
+
+	ok, err := w.filters[a].Match(r)
+	if err != nil {
+		e.Reason = "condition cannot be evaluated"
+		e.Err = err
+		return e
+	}
+	if !ok {
+		e.Reason = fmt.Sprintf("condition %q is not satisfied", a.FilterCond)
+		return e
+	}
+	return nil
+}
+
+func (w *Workflow) action(status, name string) *ProcessAction {
+	for _, a := range w.Actions {
+		if a.From == status && a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// CheckTransitions は UpdateStatuses の前に全ての更新が実行できるかを確認する
+// 同じレコードの更新が複数ある場合は、前の更新の後のステータスで確認する
+// 実行できない更新がある場合は TransitionErrors を返す
+func (w *Workflow) CheckTransitions(rs []*Record, us []*StatusUpdate) error {
+	records := make(map[string]*Record, len(rs))
+	statuses := make(map[string]string, len(rs))
+	for _, r := range rs {
+		records[r.ID] = r
+		statuses[r.ID], _ = recordStatus(r)
+	}
+
+	var es TransitionErrors
+	for _, u := range us {
+		r, ok := records[u.ID]
+		if !ok {
+			es = append(es, &TransitionError{RecordID: u.ID, Action: u.Action, Reason: "record is not given"})
+			continue
+		}
+
+		status := statuses[u.ID]
+		if err := w.canTake(r, status, u.Action); err != nil {
+			es = append(es, err.(*TransitionError))
+			continue
+		}
+		statuses[u.ID] = w.action(status, u.Action).To
+	}
+
+	if len(es) == 0 {
+		return nil
+	}
+	return es
+}
+
+// recordStatus はレコードのステータスを返す
+func recordStatus(r *Record) (string, bool) {
+	for _, f := range r.Fields {
+		if s, ok := f.(StatusField); ok {
+			return string(s), true
+		}
+	}
+	return "", false
+}
+
+// DOT は状態遷移を Graphviz の DOT 形式で返す
+// ステータスには作業者、アクションには条件を表示する
+func (w *Workflow) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph workflow {\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, s := range w.States {
+		label := s.Name
+		if s.Assignee != nil && len(s.Assignee.Entities) > 0 {
+			codes := make([]string, len(s.Assignee.Entities))
+			for i, e := range s.Assignee.Entities {
+				codes[i] = e.Entity.Code
+				if codes[i] == "" {
+					codes[i] = e.Entity.Type
+				}
+			}
+			label += fmt.Sprintf("\n作業者(%s): %s", s.Assignee.Type, strings.Join(codes, ", "))
+		}
+		fmt.Fprintf(&b, "  %s [label=%s];\n", dotQuote(s.Name), dotQuote(label))
+	}
+
+	for _, a := range w.Actions {
+		label := a.Name
+		if a.FilterCond != "" {
+			label += "\n[" + a.FilterCond + "]"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(a.From), dotQuote(a.To), dotQuote(label))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
@@ -0,0 +1,130 @@
+package kintone
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func newTestWorkflow(t *testing.T) *Workflow {
+	w, err := NewWorkflow(&ProcessManagement{
+		Enable: true,
+		States: map[string]*ProcessState{
+			"完了":  {Name: "完了", Index: 2},
+			"未処理": {Name: "未処理", Index: 0},
+			"確認中": {
+				Name:  "確認中",
+				Index: 1,
+				Assignee: &ProcessAssignee{
+					Type:     "ONE",
+					Entities: []*ProcessEntity{{Entity: Entity{Type: "USER", Code: "sato"}}},
+				},
+			},
+		},
+		Actions: []*ProcessAction{
+			{Name: "確認する", From: "未処理", To: "確認中"},
+			{Name: "承認する", From: "確認中", To: "完了", FilterCond: `amount < 10000`},
+			{Name: "差し戻す", From: "確認中", To: "未処理"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestWorkflow(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	if w.InitialState().Name != "未処理" || w.States[2].Name != "完了" {
+		t.Errorf("unexpected states: %v", w.States)
+	}
+	if as := w.ActionsFrom("確認中"); len(as) != 2 || as[0].Name != "承認する" {
+		t.Errorf("unexpected actions: %v", as)
+	}
+	if a := w.Assignee("確認中"); a == nil || a.Entities[0].Entity.Code != "sato" {
+		t.Errorf("unexpected assignee: %v", a)
+	}
+
+	r := &Record{ID: "1", Fields: Fields{"status": StatusField("確認中"), "amount": NumberField(20000)}}
+	if err := w.CanTake(r, "差し戻す"); err != nil {
+		t.Error(err)
+	}
+	err := w.CanTake(r, "承認する")
+	if e, ok := err.(*TransitionError); !ok || !strings.Contains(e.Reason, "amount < 10000") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := w.CanTake(r, "確認する"); err == nil {
+		t.Error("expected error for action from another status")
+	}
+
+	if _, err := NewWorkflow(&ProcessManagement{Actions: []*ProcessAction{{FilterCond: `amount >`}}}); err == nil {
+		t.Error("expected error for invalid filter")
+	}
+}
+
+func TestWorkflowFunctionFilter(t *testing.T) {
+	w, err := NewWorkflow(&ProcessManagement{
+		Enable:  true,
+		States:  map[string]*ProcessState{"未処理": {Name: "未処理"}, "完了": {Name: "完了", Index: 1}},
+		Actions: []*ProcessAction{{Name: "完了する", From: "未処理", To: "完了", FilterCond: `due < TODAY()`}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Record{ID: "1", Fields: Fields{"status": StatusField("未処理"), "due": DateField{}}}
+	err = w.CanTake(r, "完了する")
+	e, ok := err.(*TransitionError)
+	if !ok || errors.Cause(e) != ErrUnsupportedFilter {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckTransitions(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	rs := []*Record{
+		{ID: "1", Fields: Fields{"status": StatusField("未処理"), "amount": NumberField(100)}},
+		{ID: "2", Fields: Fields{"status": StatusField("確認中"), "amount": NumberField(20000)}},
+	}
+	us := []*StatusUpdate{
+		{ID: "1", Action: "確認する"},
+		{ID: "1", Action: "承認する"}, // 確認中になった後のアクション
+		{ID: "2", Action: "承認する"},
+		{ID: "3", Action: "確認する"},
+	}
+
+	err := w.CheckTransitions(rs, us)
+	es, ok := err.(TransitionErrors)
+	if !ok || len(es) != 2 {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	if es[0].RecordID != "2" || es[0].Status != "確認中" || es[1].RecordID != "3" {
+		t.Errorf("unexpected errors: %v", es)
+	}
+
+	if err := w.CheckTransitions(rs[:1], us[:2]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWorkflowDOT(t *testing.T) {
+	w := newTestWorkflow(t)
+
+	dot := w.DOT()
+	for _, s := range []string{
+		"digraph workflow {",
+		`"確認中" [label="確認中\n作業者(ONE): sato"];`,
+		`"確認中" -> "完了" [label="承認する\n[amount < 10000]"];`,
+	} {
+		if !strings.Contains(dot, s) {
+			t.Errorf("%s is not in:\n%s", s, dot)
+		}
+	}
+
+	if s := dotQuote(`a "b" \c`); s != `"a \"b\" \\c"` {
+		t.Errorf("unexpected quote: %s", s)
+	}
+}